@@ -1,7 +1,192 @@
 package main
 
-import "fmt"
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/danny-molnar/crossword/internal/api"
+	"github.com/danny-molnar/crossword/internal/library"
+	"github.com/danny-molnar/crossword/internal/realtime"
+	"github.com/danny-molnar/crossword/internal/store"
+	"github.com/danny-molnar/crossword/internal/tools"
+)
+
+type config struct {
+	addr       string
+	wordlist   string
+	puzzlesDir string
+	creatorKey string
+
+	readTimeout     time.Duration
+	writeTimeout    time.Duration
+	idleTimeout     time.Duration
+	shutdownTimeout time.Duration
+	puzzlesPoll     time.Duration
+}
+
+// loadConfig reads flags, each defaulting to its CROSSWORD_* environment
+// variable and then to a built-in value. Flags win over the environment.
+func loadConfig(args []string) (config, error) {
+	var cfg config
+	fs := flag.NewFlagSet("api", flag.ContinueOnError)
+	fs.StringVar(&cfg.addr, "addr", envString("CROSSWORD_ADDR", ":8080"), "listen address (CROSSWORD_ADDR)")
+	fs.StringVar(&cfg.wordlist, "wordlist", envString("CROSSWORD_WORDLIST", "wordlists/english.txt"), "wordlist for the tools endpoints (CROSSWORD_WORDLIST)")
+	fs.StringVar(&cfg.puzzlesDir, "puzzles", envString("CROSSWORD_PUZZLES_DIR", "puzzles"), "directory of puzzle JSON files to load (CROSSWORD_PUZZLES_DIR)")
+	fs.StringVar(&cfg.creatorKey, "creator-key", envString("CROSSWORD_CREATOR_KEY", ""), "bearer token for the creator routes; empty disables them (CROSSWORD_CREATOR_KEY)")
+
+	durations := []struct {
+		dst   *time.Duration
+		name  string
+		env   string
+		def   time.Duration
+		usage string
+	}{
+		{&cfg.readTimeout, "read-timeout", "CROSSWORD_READ_TIMEOUT", 10 * time.Second, "maximum time to read a request"},
+		{&cfg.writeTimeout, "write-timeout", "CROSSWORD_WRITE_TIMEOUT", 30 * time.Second, "maximum time to write a response"},
+		{&cfg.idleTimeout, "idle-timeout", "CROSSWORD_IDLE_TIMEOUT", 120 * time.Second, "how long idle keep-alive connections stay open"},
+		{&cfg.shutdownTimeout, "shutdown-timeout", "CROSSWORD_SHUTDOWN_TIMEOUT", 15 * time.Second, "how long to wait for requests to finish on SIGTERM"},
+		{&cfg.puzzlesPoll, "puzzles-poll", "CROSSWORD_PUZZLES_POLL", 2 * time.Second, "how often to look for new or changed puzzle files; 0 loads them once"},
+	}
+	for _, d := range durations {
+		fs.DurationVar(d.dst, d.name, d.def, d.usage+" ("+d.env+")")
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return config{}, err
+	}
+
+	// Durations from the environment are parsed only for flags that weren't
+	// given, so a bad variable doesn't stop a flag from overriding it.
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	for _, d := range durations {
+		v, ok := os.LookupEnv(d.env)
+		if !ok || set[d.name] {
+			continue
+		}
+		dur, err := time.ParseDuration(v)
+		if err != nil {
+			return config{}, fmt.Errorf("%s: %w", d.env, err)
+		}
+		*d.dst = dur
+	}
+	return cfg, nil
+}
+
+func envString(key, def string) string {
+	if v, ok := os.LookupEnv(key); ok {
+		return v
+	}
+	return def
+}
 
 func main() {
-	fmt.Println("crossword API starting (stub)")
+	cfg, err := loadConfig(os.Args[1:])
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		log.Fatalf("config: %v", err)
+	}
+	if err := run(cfg); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(cfg config) error {
+	wl, err := tools.LoadWordlist(cfg.wordlist)
+	if err != nil {
+		return err
+	}
+	log.Printf("loaded %d words from %s", len(wl.Words), cfg.wordlist)
+
+	st := store.NewMemoryStore()
+	// Session edits reach connected solvers through the store's update path.
+	hub := realtime.NewHub()
+	st.Sessions.OnUpdate(hub.SessionUpdated)
+
+	lib := library.New(cfg.puzzlesDir, st.Puzzles)
+	rep, err := lib.Load()
+	logReport(cfg.puzzlesDir, rep, err)
+
+	// Request contexts derive from base, so cancelling it on shutdown ends
+	// event streams, which would otherwise hold Shutdown until its timeout.
+	base, cancelBase := context.WithCancel(context.Background())
+	defer cancelBase()
+
+	srv := &http.Server{
+		Addr:         cfg.addr,
+		Handler:      api.NewRouter(st, hub, wl, api.Config{CreatorKey: cfg.creatorKey}),
+		ReadTimeout:  cfg.readTimeout,
+		WriteTimeout: cfg.writeTimeout, // event streams lift it for themselves
+		IdleTimeout:  cfg.idleTimeout,
+		BaseContext:  func(net.Listener) context.Context { return base },
+	}
+	srv.RegisterOnShutdown(cancelBase)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	if cfg.puzzlesPoll > 0 {
+		go lib.Watch(ctx, cfg.puzzlesPoll, func(rep library.Report, err error) {
+			logReport(cfg.puzzlesDir, rep, err)
+		})
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		log.Printf("crossword API listening on %s", cfg.addr)
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+	}
+	stop()
+
+	// Stop accepting connections and let in-flight requests finish.
+	log.Printf("shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("shutdown: %w", err)
+	}
+	if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// logReport logs what a library load stored and which files failed, with
+// their problems.
+func logReport(dir string, rep library.Report, err error) {
+	if err != nil {
+		log.Printf("puzzles: %v", err)
+		return
+	}
+	for _, l := range rep.Loaded {
+		log.Printf("puzzles: loaded %s as %s revision %d", filepath.Join(dir, l.File), l.PuzzleID, l.Revision)
+	}
+	for _, f := range rep.Failed {
+		if len(f.Problems) == 0 {
+			log.Printf("puzzles: %s: %v", filepath.Join(dir, f.File), f.Err)
+			continue
+		}
+		log.Printf("puzzles: %s: %d problems", filepath.Join(dir, f.File), len(f.Problems))
+		for _, p := range f.Problems {
+			log.Printf("puzzles:   %s: %s", p.Field, p.Message)
+		}
+	}
 }
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoadConfig(t *testing.T) {
+	cfg, err := loadConfig(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.addr != ":8080" || cfg.readTimeout != 10*time.Second || cfg.puzzlesPoll != 2*time.Second || cfg.creatorKey != "" {
+		t.Fatalf("defaults = %+v", cfg)
+	}
+
+	t.Setenv("CROSSWORD_ADDR", ":9000")
+	t.Setenv("CROSSWORD_WRITE_TIMEOUT", "45s")
+	cfg, err = loadConfig(nil)
+	if err != nil || cfg.addr != ":9000" || cfg.writeTimeout != 45*time.Second {
+		t.Fatalf("from environment = %+v, %v", cfg, err)
+	}
+
+	// Flags win over the environment.
+	cfg, err = loadConfig([]string{"-addr", ":7000", "-write-timeout", "5s"})
+	if err != nil || cfg.addr != ":7000" || cfg.writeTimeout != 5*time.Second {
+		t.Fatalf("flags over environment = %+v, %v", cfg, err)
+	}
+}
+
+func TestLoadConfig_BadDurationEnv(t *testing.T) {
+	t.Setenv("CROSSWORD_IDLE_TIMEOUT", "soon")
+
+	if _, err := loadConfig(nil); err == nil {
+		t.Fatal("bad CROSSWORD_IDLE_TIMEOUT: want error")
+	}
+	// The flag overrides the variable, so it is never parsed.
+	cfg, err := loadConfig([]string{"-idle-timeout", "1m"})
+	if err != nil || cfg.idleTimeout != time.Minute {
+		t.Fatalf("flag over bad environment = %+v, %v", cfg, err)
+	}
+}
@@ -0,0 +1,131 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/danny-molnar/crossword/internal/domain"
+	"github.com/danny-molnar/crossword/internal/realtime"
+	"github.com/danny-molnar/crossword/internal/store"
+	"github.com/danny-molnar/crossword/internal/tools"
+)
+
+// newPuzzleJSON is a 3x3 puzzle with clues but no entries:
+//
+//	C A T
+//	A # O
+//	B E E
+const newPuzzleJSON = `{
+  "id": "puz_new", "title": "New", "type": "quick", "rows": 3, "cols": 3,
+  "grid": {"cells": [
+    [{"r":0,"c":0,"solution":"C"},{"r":0,"c":1,"solution":"A"},{"r":0,"c":2,"solution":"T"}],
+    [{"r":1,"c":0,"solution":"A"},{"r":1,"c":1,"block":true},{"r":1,"c":2,"solution":"O"}],
+    [{"r":2,"c":0,"solution":"B"},{"r":2,"c":1,"solution":"E"},{"r":2,"c":2,"solution":"E"}]
+  ]},
+  "clues": [{"entryId":"1a","text":"Pet"}, {"entryId":"1d","text":"Taxi"}]
+}`
+
+// creator sends a creator request with the test key.
+func creator(t *testing.T, h http.Handler, method, path, ifMatch, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+testCreatorKey)
+	if ifMatch != "" {
+		req.Header.Set("If-Match", ifMatch)
+	}
+	return send(h, req)
+}
+
+func TestCreator_Auth(t *testing.T) {
+	disabled := NewRouter(store.NewMemoryStore(), realtime.NewHub(), &tools.Wordlist{}, Config{})
+	rec := creator(t, disabled, http.MethodPost, "/v1/puzzles", "", newPuzzleJSON)
+	if rec.Code != http.StatusForbidden || decodeError(t, rec).Error.Code != "creator_disabled" {
+		t.Fatalf("disabled: status=%d body=%s", rec.Code, rec.Body)
+	}
+
+	h := newTestServer(t)
+	for _, auth := range []string{"", "Bearer wrong", testCreatorKey} {
+		req := httptest.NewRequest(http.MethodGet, "/v1/puzzles/p1/full", nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		rec := send(h, req)
+		if rec.Code != http.StatusUnauthorized || decodeError(t, rec).Error.Code != "unauthorized" {
+			t.Fatalf("Authorization %q: status=%d body=%s", auth, rec.Code, rec.Body)
+		}
+	}
+}
+
+func TestCreator_CreateAndUpdate(t *testing.T) {
+	h := newTestServer(t)
+
+	rec := creator(t, h, http.MethodPost, "/v1/puzzles", "", newPuzzleJSON)
+	if rec.Code != http.StatusCreated || rec.Header().Get("ETag") != `"1"` {
+		t.Fatalf("create: status=%d etag=%s body=%s", rec.Code, rec.Header().Get("ETag"), rec.Body)
+	}
+	var created domain.Puzzle
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatal(err)
+	}
+	// Entries left out are generated, so the clues have entries to belong to.
+	if created.Revision != 1 || len(created.Entries) != 4 {
+		t.Fatalf("created = revision %d, %d entries", created.Revision, len(created.Entries))
+	}
+
+	rec = creator(t, h, http.MethodPost, "/v1/puzzles", "", newPuzzleJSON)
+	if rec.Code != http.StatusConflict || decodeError(t, rec).Error.Code != "puzzle_exists" {
+		t.Fatalf("duplicate: status=%d body=%s", rec.Code, rec.Body)
+	}
+
+	edited := strings.Replace(newPuzzleJSON, `"title": "New"`, `"title": "Edited"`, 1)
+	rec = creator(t, h, http.MethodPut, "/v1/puzzles/puz_new", `W/"1"`, edited)
+	if rec.Code != http.StatusPreconditionFailed || rec.Header().Get("ETag") != `"1"` {
+		t.Fatalf("weak update: status=%d etag=%s body=%s", rec.Code, rec.Header().Get("ETag"), rec.Body)
+	}
+	rec = creator(t, h, http.MethodPut, "/v1/puzzles/puz_new", `"1"`, edited)
+	if rec.Code != http.StatusOK || rec.Header().Get("ETag") != `"2"` {
+		t.Fatalf("update: status=%d etag=%s body=%s", rec.Code, rec.Header().Get("ETag"), rec.Body)
+	}
+	rec = creator(t, h, http.MethodGet, "/v1/puzzles/puz_new/full?revision=1", "", "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"title":"New"`) {
+		t.Fatalf("revision 1 after update: status=%d body=%s", rec.Code, rec.Body)
+	}
+
+	// Only the creator view carries solutions.
+	full := creator(t, h, http.MethodGet, "/v1/puzzles/puz_new/full", "", "")
+	if full.Code != http.StatusOK || !strings.Contains(full.Body.String(), `"solution":"C"`) || !strings.Contains(full.Body.String(), `"title":"Edited"`) {
+		t.Fatalf("full: status=%d body=%s", full.Code, full.Body)
+	}
+	public := do(t, h, http.MethodGet, "/v1/puzzles/puz_new", "", "")
+	if public.Code != http.StatusOK || strings.Contains(public.Body.String(), `"solution"`) || strings.Contains(public.Body.String(), `"answer"`) {
+		t.Fatalf("public: status=%d body=%s", public.Code, public.Body)
+	}
+}
+
+func TestCreator_ValidationProblems(t *testing.T) {
+	h := newTestServer(t)
+
+	for name, body := range map[string]string{
+		"bad clue": strings.Replace(newPuzzleJSON, `"entryId":"1a"`, `"entryId":"9a"`, 1),
+		// Dimensions and entries but no cells used to panic.
+		"no cells": `{"title":"X","type":"quick","rows":3,"cols":3,"entries":[{"id":"1a","dir":"across","num":1,"cells":[{"r":0,"c":0}]}]}`,
+	} {
+		for _, method := range []string{http.MethodPost, http.MethodPut} {
+			path := "/v1/puzzles"
+			if method == http.MethodPut {
+				path = "/v1/puzzles/p1"
+				body = strings.Replace(body, `"id": "puz_new", `, ``, 1)
+			}
+			rec := creator(t, h, method, path, "", body)
+			if rec.Code != http.StatusUnprocessableEntity {
+				t.Fatalf("%s %s: status=%d body=%s", method, name, rec.Code, rec.Body)
+			}
+			if e := decodeError(t, rec).Error; e.Code != "validation_failed" || len(e.Problems) == 0 || e.Problems[0].Field == "" {
+				t.Fatalf("%s %s: body=%s", method, name, rec.Body)
+			}
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/danny-molnar/crossword/internal/domain"
+)
+
+// scopeRequest is the body of check and reveal requests.
+type scopeRequest struct {
+	Scope   domain.Scope `json:"scope"`
+	Cell    string       `json:"cell,omitempty"`    // "r,c", for scope=cell
+	EntryID string       `json:"entryId,omitempty"` // for scope=entry
+}
+
+// decodeScope reads a scopeRequest and resolves it against the puzzle,
+// writing a 400 and returning ok=false if it is malformed.
+func decodeScope(w http.ResponseWriter, r *http.Request, p domain.Puzzle) ([]domain.CellRef, bool) {
+	var req scopeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, r, http.StatusBadRequest, codeInvalidJSON, "invalid json")
+		return nil, false
+	}
+	cells, err := domain.ScopeCells(p, req.Scope, req.Cell, req.EntryID)
+	if err != nil {
+		writeErr(w, r, http.StatusBadRequest, codeInvalidRequest, err.Error())
+		return nil, false
+	}
+	return cells, true
+}
+
+type checkSessionResponse struct {
+	// Incorrect lists the filled cells in scope that are wrong, in scope
+	// order.
+	Incorrect []string `json:"incorrect"`
+	// Checked lists the filled cells in scope this check covered.
+	Checked []string            `json:"checked"`
+	Session domain.SolveSession `json:"session"`
+}
+
+// CheckSession checks the filled cells in a cell, an entry or the whole grid
+// and reports which are wrong. It never reveals correct letters. Each call
+// counts as one check, and the checked cells are recorded on the session.
+func (h *Handler) CheckSession(w http.ResponseWriter, r *http.Request) {
+	sid := chi.URLParam(r, "sid")
+	want, ok := ifMatch(w, r)
+	if !ok {
+		return
+	}
+
+	_, p, ok := h.sessionPuzzle(w, r, sid)
+	if !ok {
+		return
+	}
+	cells, ok := decodeScope(w, r, p)
+	if !ok {
+		return
+	}
+
+	resp := checkSessionResponse{Incorrect: []string{}, Checked: []string{}}
+	updated, ok := h.updateSession(w, r, sid, want, p, func(cur domain.SolveSession) domain.SolveSession {
+		res := domain.CheckSession(p, cur)
+
+		checked := make(map[string]bool, len(cur.Checked)+len(cells))
+		for k, v := range cur.Checked {
+			checked[k] = v
+		}
+		for _, cr := range cells {
+			key := domain.CellKey(cr.R, cr.C)
+			switch res.Cells[key] {
+			case domain.CellIncorrect:
+				resp.Incorrect = append(resp.Incorrect, key)
+				fallthrough
+			case domain.CellCorrect:
+				resp.Checked = append(resp.Checked, key)
+				checked[key] = true
+			}
+		}
+
+		cur.Checked = checked
+		cur.ChecksUsed++
+		return cur
+	})
+	if !ok {
+		return
+	}
+
+	resp.Session = updated
+	writeJSON(w, http.StatusOK, resp)
+}
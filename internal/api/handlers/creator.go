@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/danny-molnar/crossword/internal/domain"
+	"github.com/danny-molnar/crossword/internal/util"
+)
+
+// RequireCreator guards creator routes. Requests must carry
+// "Authorization: Bearer <creator key>". With no key configured the creator
+// API is disabled.
+func (h *Handler) RequireCreator(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h.creatorKey == "" {
+			writeErr(w, r, http.StatusForbidden, codeCreatorDisabled, "creator api disabled")
+			return
+		}
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(h.creatorKey)) != 1 {
+			writeErr(w, r, http.StatusUnauthorized, codeUnauthorized, "creator key required")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// CreatePuzzle validates and stores a new puzzle. An ID is assigned unless
+// the body carries one. Entries left out are generated from the grid; with
+// ?generateEntries=true supplied ones are reconciled with the grid too,
+// keeping their IDs, enums and answers, before validation.
+func (h *Handler) CreatePuzzle(w http.ResponseWriter, r *http.Request) {
+	p, ok := decodePuzzle(w, r)
+	if !ok {
+		return
+	}
+	if p.ID == "" {
+		p.ID = util.NewID()
+	} else if _, err := h.store.Puzzles.GetPuzzle(p.ID); err == nil {
+		writeErr(w, r, http.StatusConflict, codePuzzleExists, "puzzle already exists")
+		return
+	}
+
+	if err := domain.ValidatePuzzle(p); err != nil {
+		writeValidation(w, r, err)
+		return
+	}
+
+	stored, err := h.store.Puzzles.PutPuzzleIfRevision(p, 0)
+	if err != nil {
+		// Created by another request since the check above.
+		writeErr(w, r, http.StatusConflict, codePuzzleExists, "puzzle already exists")
+		return
+	}
+	setETag(w, stored.Revision)
+	writeJSON(w, http.StatusCreated, stored)
+}
+
+// UpdatePuzzle validates a puzzle and stores it as a new revision. Sessions
+// on earlier revisions are unaffected until migrated. With If-Match the edit
+// is only stored if it names the latest revision; otherwise the response is
+// a 412 with that revision.
+func (h *Handler) UpdatePuzzle(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	want, ok := ifMatch(w, r)
+	if !ok {
+		return
+	}
+
+	cur, err := h.store.Puzzles.GetPuzzle(id)
+	if err != nil {
+		writeErr(w, r, http.StatusNotFound, codePuzzleNotFound, "puzzle not found")
+		return
+	}
+	if !want.matches(cur.Revision) {
+		writeStale(w, r, cur.Revision, cur)
+		return
+	}
+
+	p, ok := decodePuzzle(w, r)
+	if !ok {
+		return
+	}
+	if p.ID != "" && p.ID != id {
+		writeErr(w, r, http.StatusBadRequest, codeInvalidRequest, "puzzle id does not match url")
+		return
+	}
+	p.ID = id
+
+	if err := domain.ValidatePuzzle(p); err != nil {
+		writeValidation(w, r, err)
+		return
+	}
+
+	var stored domain.Puzzle
+	if want.any {
+		stored = h.store.Puzzles.PutPuzzle(p)
+	} else if stored, err = h.store.Puzzles.PutPuzzleIfRevision(p, cur.Revision); err != nil {
+		// Edited by another request since the check above.
+		writeStale(w, r, stored.Revision, stored)
+		return
+	}
+	setETag(w, stored.Revision)
+	writeJSON(w, http.StatusOK, stored)
+}
+
+// GetPuzzleFull returns the full puzzle, answers and solutions included.
+// Like GetPuzzle it serves the latest revision unless ?revision is given.
+func (h *Handler) GetPuzzleFull(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	p, ok := h.puzzleAtRevision(w, r, id)
+	if !ok {
+		return
+	}
+
+	setETag(w, p.Revision)
+	writeJSON(w, http.StatusOK, p)
+}
+
+// decodePuzzle reads a puzzle from the request body, fills in grid
+// dimensions left out of it, and generates entries if asked to or if it
+// has none.
+func decodePuzzle(w http.ResponseWriter, r *http.Request) (domain.Puzzle, bool) {
+	var p domain.Puzzle
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		writeErr(w, r, http.StatusBadRequest, codeInvalidJSON, "invalid json")
+		return domain.Puzzle{}, false
+	}
+
+	p.NormalizeGrid()
+
+	// Revisions are assigned by the store.
+	p.Revision = 0
+
+	var gen bool
+	if q := r.URL.Query().Get("generateEntries"); q != "" {
+		var err error
+		if gen, err = strconv.ParseBool(q); err != nil {
+			writeErr(w, r, http.StatusBadRequest, codeInvalidParameter, "invalid generateEntries")
+			return domain.Puzzle{}, false
+		}
+	}
+
+	// Generation needs a well-formed grid; otherwise leave the entries
+	// alone and let validation report the grid problems. Puzzles without
+	// entries always get them, as the library does, so their clues have
+	// entries to belong to.
+	if gen || len(p.Entries) == 0 {
+		if err := domain.ValidatePuzzle(domain.Puzzle{Rows: p.Rows, Cols: p.Cols, Grid: p.Grid}); err == nil {
+			p.Entries = domain.ReconcileEntries(p.Grid, p.Entries)
+		}
+	}
+	return p, true
+}
@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/danny-molnar/crossword/internal/domain"
+	"github.com/danny-molnar/crossword/internal/openapi"
+	"github.com/danny-molnar/crossword/internal/realtime"
+	"github.com/danny-molnar/crossword/internal/tools"
+)
+
+// Spec describes the API for the OpenAPI document. Every route the router
+// registers needs an entry in routeDocs, keyed by method and full pattern;
+// the api package's tests check that none is missing.
+func Spec() *openapi.Spec {
+	return openapi.NewSpec(openapi.Info{Title: "Crossword API", Version: "v1"}, routeDocs, errorResponse{})
+}
+
+var revisionParam = openapi.Param{Name: "revision", Type: "integer", Description: "Puzzle revision; the latest if left out."}
+
+var routeDocs = map[string]openapi.Operation{
+	"GET /v1/health": {
+		Summary:     "Health check",
+		Tags:        []string{"meta"},
+		Response:    "",
+		ContentType: "text/plain",
+	},
+	"GET /v1/openapi.json": {
+		Summary:  "This document",
+		Tags:     []string{"meta"},
+		Response: map[string]any{},
+	},
+
+	"GET /v1/puzzles": {
+		Summary: "List puzzles, most recently created first",
+		Tags:    []string{"puzzles"},
+		Query: []openapi.Param{
+			{Name: "type", Description: "cryptic or quick"},
+			{Name: "rows", Type: "integer"},
+			{Name: "cols", Type: "integer"},
+			{Name: "from", Description: "Earliest publication date, YYYY-MM-DD."},
+			{Name: "to", Description: "Latest publication date, YYYY-MM-DD."},
+			{Name: "author", Description: "Case-insensitive substring."},
+			{Name: "limit", Type: "integer"},
+			{Name: "cursor", Description: "nextCursor of the previous page."},
+		},
+		Response: listPuzzlesResponse{},
+	},
+	"GET /v1/puzzles/{id}": {
+		Summary:  "Get a puzzle without answers or solutions",
+		Tags:     []string{"puzzles"},
+		Query:    []openapi.Param{revisionParam},
+		ETag:     true,
+		Response: domain.PuzzlePublic{},
+	},
+	"POST /v1/puzzles": {
+		Summary:  "Create a puzzle",
+		Tags:     []string{"creator"},
+		Creator:  true,
+		Query:    []openapi.Param{{Name: "generateEntries", Type: "boolean", Description: "Reconcile supplied entries with the grid; entries left out are always generated."}},
+		Request:  domain.Puzzle{},
+		Status:   http.StatusCreated,
+		ETag:     true,
+		Response: domain.Puzzle{},
+	},
+	"PUT /v1/puzzles/{id}": {
+		Summary:  "Store a new revision of a puzzle",
+		Tags:     []string{"creator"},
+		Creator:  true,
+		Query:    []openapi.Param{{Name: "generateEntries", Type: "boolean", Description: "Reconcile supplied entries with the grid; entries left out are always generated."}},
+		IfMatch:  true,
+		Errors:   map[int]any{http.StatusPreconditionFailed: stalePuzzle{}},
+		Request:  domain.Puzzle{},
+		ETag:     true,
+		Response: domain.Puzzle{},
+	},
+	"GET /v1/puzzles/{id}/full": {
+		Summary:  "Get a puzzle with answers and solutions",
+		Tags:     []string{"creator"},
+		Creator:  true,
+		Query:    []openapi.Param{revisionParam},
+		ETag:     true,
+		Response: domain.Puzzle{},
+	},
+
+	"POST /v1/puzzles/{id}/sessions": {
+		Summary:  "Start a solve session on the latest revision",
+		Tags:     []string{"sessions"},
+		Status:   http.StatusCreated,
+		ETag:     true,
+		Response: createSessionResponse{},
+	},
+	"GET /v1/sessions/{sid}": {
+		Summary:  "Get a session",
+		Tags:     []string{"sessions"},
+		ETag:     true,
+		Response: domain.SolveSession{},
+	},
+	"PUT /v1/sessions/{sid}": {
+		Summary:  "Replace a session's fill and pencil marks",
+		Tags:     []string{"sessions"},
+		IfMatch:  true,
+		Errors:   map[int]any{http.StatusPreconditionFailed: staleSession{}},
+		ETag:     true,
+		Request:  updateSessionRequest{},
+		Response: domain.SolveSession{},
+	},
+	"PATCH /v1/sessions/{sid}": {
+		Summary:  "Set or clear individual cells and pencil marks",
+		Tags:     []string{"sessions"},
+		IfMatch:  true,
+		Errors:   map[int]any{http.StatusPreconditionFailed: staleSession{}},
+		ETag:     true,
+		Request:  patchSessionRequest{},
+		Response: domain.SolveSession{},
+	},
+	"POST /v1/sessions/{sid}/migrate": {
+		Summary:     "Move a session onto another puzzle revision",
+		Tags:        []string{"sessions"},
+		Description: "Responds 409 with the conflicts unless force is set, which drops the conflicting values.",
+		IfMatch:     true,
+		Errors:      map[int]any{http.StatusConflict: migrateSessionResponse{}, http.StatusPreconditionFailed: staleSession{}},
+		ETag:        true,
+		Request:     migrateSessionRequest{},
+		Response:    migrateSessionResponse{},
+	},
+	"POST /v1/sessions/{sid}/check": {
+		Summary:  "Check a cell, an entry or the grid",
+		Tags:     []string{"sessions"},
+		IfMatch:  true,
+		Errors:   map[int]any{http.StatusPreconditionFailed: staleSession{}},
+		ETag:     true,
+		Request:  scopeRequest{},
+		Response: checkSessionResponse{},
+	},
+	"POST /v1/sessions/{sid}/reveal": {
+		Summary:  "Reveal a cell, an entry or the grid",
+		Tags:     []string{"sessions"},
+		IfMatch:  true,
+		Errors:   map[int]any{http.StatusPreconditionFailed: staleSession{}},
+		ETag:     true,
+		Request:  scopeRequest{},
+		Response: revealSessionResponse{},
+	},
+	"POST /v1/sessions/{sid}/pause": {
+		Summary:  "Pause the solve timer",
+		Tags:     []string{"sessions"},
+		IfMatch:  true,
+		Errors:   map[int]any{http.StatusPreconditionFailed: staleSession{}},
+		ETag:     true,
+		Response: domain.SolveSession{},
+	},
+	"POST /v1/sessions/{sid}/resume": {
+		Summary:  "Resume the solve timer",
+		Tags:     []string{"sessions"},
+		IfMatch:  true,
+		Errors:   map[int]any{http.StatusPreconditionFailed: staleSession{}},
+		ETag:     true,
+		Response: domain.SolveSession{},
+	},
+	"GET /v1/sessions/{sid}/events": {
+		Summary:     "Stream a session's edits, cursors and presence",
+		Tags:        []string{"sessions"},
+		Description: "Server-sent events; each data line is one event. The first is a snapshot.",
+		Query: []openapi.Param{
+			{Name: "participant", Description: "Solver ID; assigned and returned in X-Participant if left out. X-Participant-Token holds the token for moving your cursor."},
+			{Name: "name", Description: "Display name shown to the others."},
+		},
+		Response:    realtime.Event{},
+		ContentType: "text/event-stream",
+	},
+	"POST /v1/sessions/{sid}/cursor": {
+		Summary: "Move your cursor",
+		Tags:    []string{"sessions"},
+		Request: cursorRequest{},
+		Status:  http.StatusNoContent,
+	},
+
+	"GET /v1/tools/anagram": {
+		Summary: "Exact anagrams from the wordlist",
+		Tags:    []string{"tools"},
+		Query: []openapi.Param{
+			{Name: "letters", Required: true},
+			{Name: "len", Type: "integer"},
+		},
+		Response: []tools.AnagramResult{},
+	},
+	"GET /v1/tools/pattern": {
+		Summary: "Words matching a pattern like TR?C?",
+		Tags:    []string{"tools"},
+		Query: []openapi.Param{
+			{Name: "pattern", Required: true},
+			{Name: "len", Type: "integer"},
+		},
+		Response: []tools.PatternResult{},
+	},
+}
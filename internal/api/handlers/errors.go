@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/danny-molnar/crossword/internal/domain"
+)
+
+// Error codes. Clients switch on these, so once published they don't change;
+// messages are for people and may.
+const (
+	codeInvalidJSON             = "invalid_json"              // body isn't the expected JSON
+	codeInvalidParameter        = "invalid_parameter"         // bad query parameter or header
+	codeInvalidRequest          = "invalid_request"           // well-formed body that makes no sense
+	codeValidationFailed        = "validation_failed"         // see problems
+	codeUnauthorized            = "unauthorized"              // missing or wrong creator key
+	codeCreatorDisabled         = "creator_disabled"          // no creator key configured
+	codeNotFound                = "not_found"                 // no such route
+	codeMethodNotAllowed        = "method_not_allowed"        // route exists, method doesn't
+	codePuzzleNotFound          = "puzzle_not_found"          // no such puzzle
+	codeRevisionNotFound        = "revision_not_found"        // puzzle exists, revision doesn't
+	codeSessionNotFound         = "session_not_found"         // no such session
+	codePuzzleExists            = "puzzle_exists"             // create with an ID already in use
+	codeVersionMismatch         = "version_mismatch"          // If-Match is stale; see current
+	codeMigrationConflict       = "migration_conflict"        // fill doesn't fit; see conflicts
+	codeSessionCompleted        = "session_completed"         // the timer of a completed session is final
+	codeParticipantNotConnected = "participant_not_connected" // cursor without a token from the participant's event stream
+	codeInternal                = "internal_error"            // the server failed; not the request's fault
+)
+
+// apiError is the body of every error response, under "error".
+type apiError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// RequestID matches the X-Request-Id the request was logged under.
+	RequestID string           `json:"requestId,omitempty"`
+	Problems  []domain.Problem `json:"problems,omitempty"`
+}
+
+type errorResponse struct {
+	Error apiError `json:"error"`
+}
+
+func newError(r *http.Request, code, msg string) apiError {
+	return apiError{Code: code, Message: msg, RequestID: middleware.GetReqID(r.Context())}
+}
+
+func writeErr(w http.ResponseWriter, r *http.Request, status int, code, msg string) {
+	writeJSON(w, status, errorResponse{Error: newError(r, code, msg)})
+}
+
+// writeValidation writes a 422 listing each problem of a ValidationError.
+func writeValidation(w http.ResponseWriter, r *http.Request, err error) {
+	var verr domain.ValidationError
+	if !errors.As(err, &verr) {
+		writeErr(w, r, http.StatusUnprocessableEntity, codeValidationFailed, err.Error())
+		return
+	}
+	e := newError(r, codeValidationFailed, "validation failed")
+	e.Problems = verr.Problems
+	writeJSON(w, http.StatusUnprocessableEntity, errorResponse{Error: e})
+}
+
+// NotFound and MethodNotAllowed answer requests no route handles.
+func (h *Handler) NotFound(w http.ResponseWriter, r *http.Request) {
+	writeErr(w, r, http.StatusNotFound, codeNotFound, "no such route")
+}
+
+func (h *Handler) MethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	writeErr(w, r, http.StatusMethodNotAllowed, codeMethodNotAllowed, "method not allowed")
+}
+
+// InternalError answers a request the server failed to serve, for routes
+// that live outside this package.
+func (h *Handler) InternalError(w http.ResponseWriter, r *http.Request) {
+	writeErr(w, r, http.StatusInternalServerError, codeInternal, "internal error")
+}
+
+// Recover answers a request whose handler panicked with an internal error,
+// in the usual envelope, and logs the panic.
+func (h *Handler) Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			rvr := recover()
+			if rvr == nil {
+				return
+			}
+			if rvr == http.ErrAbortHandler {
+				// The server aborts the response quietly; let it.
+				panic(rvr)
+			}
+			middleware.PrintPrettyStack(rvr)
+			h.InternalError(w, r)
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/danny-molnar/crossword/internal/domain"
+)
+
+// etag formats a session version or puzzle revision as a strong ETag.
+func etag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+func setETag(w http.ResponseWriter, version int) {
+	w.Header().Set("ETag", etag(version))
+}
+
+// precondition is a parsed If-Match header.
+type precondition struct {
+	any      bool  // no header, or "*"
+	versions []int // versions named by strong ETags
+}
+
+// matches reports whether the header matches an entity at version v. Weak
+// ETags never match: If-Match uses strong comparison.
+func (m precondition) matches(v int) bool {
+	return m.any || slices.Contains(m.versions, v)
+}
+
+// expect returns the version to make a conditional store write against,
+// having seen the entity at version v: 0 (any) if the header matches
+// anything, v if it matches v, and -1 (none) otherwise.
+func (m precondition) expect(v int) int {
+	switch {
+	case m.any:
+		return 0
+	case m.matches(v):
+		return v
+	}
+	return -1
+}
+
+// ifMatch parses the request's If-Match header: "*" or a comma-separated
+// list of ETags. ETags that aren't versions of ours are allowed, they just
+// never match. If the header is malformed it writes a 400 and returns
+// ok=false.
+func ifMatch(w http.ResponseWriter, r *http.Request) (precondition, bool) {
+	v := strings.TrimSpace(strings.Join(r.Header.Values("If-Match"), ","))
+	if v == "" || v == "*" {
+		return precondition{any: true}, true
+	}
+	var m precondition
+	for _, tag := range strings.Split(v, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		weak := false
+		if rest, ok := strings.CutPrefix(tag, "W/"); ok {
+			tag, weak = rest, true
+		}
+		opaque, ok := strings.CutPrefix(tag, `"`)
+		if ok {
+			opaque, ok = strings.CutSuffix(opaque, `"`)
+		}
+		if !ok || strings.Contains(opaque, `"`) {
+			writeErr(w, r, http.StatusBadRequest, codeInvalidParameter, `If-Match must be "*" or a list of ETags`)
+			return precondition{}, false
+		}
+		if n, err := strconv.Atoi(opaque); err == nil && n > 0 && !weak {
+			m.versions = append(m.versions, n)
+		}
+	}
+	return m, true
+}
+
+type staleResponse struct {
+	Error   apiError `json:"error"`
+	Current any      `json:"current"`
+}
+
+// staleSession and stalePuzzle are staleResponse with Current typed, to
+// document the 412s of session and puzzle writes.
+type staleSession struct {
+	Error   apiError            `json:"error"`
+	Current domain.SolveSession `json:"current"`
+}
+
+type stalePuzzle struct {
+	Error   apiError      `json:"error"`
+	Current domain.Puzzle `json:"current"`
+}
+
+// writeStale answers a write whose If-Match no longer matches: a 412 with
+// the current state and its ETag, so the client can merge and retry.
+func writeStale(w http.ResponseWriter, r *http.Request, version int, current any) {
+	setETag(w, version)
+	writeJSON(w, http.StatusPreconditionFailed, staleResponse{
+		Error:   newError(r, codeVersionMismatch, "version mismatch"),
+		Current: current,
+	})
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/danny-molnar/crossword/internal/domain"
+	"github.com/danny-molnar/crossword/internal/realtime"
+	"github.com/danny-molnar/crossword/internal/util"
+)
+
+// keepAliveInterval is how often an idle event stream gets a comment line,
+// so proxies don't time it out.
+const keepAliveInterval = 15 * time.Second
+
+// SessionEvents streams a session's events as server-sent events: a
+// snapshot first, then cell edits, cursors and presence as they happen.
+// ?participant identifies the solver (one is assigned if left out and sent
+// back in the X-Participant header); ?name is shown to the others. The
+// X-Participant-Token header carries this connection's token, which
+// SetCursor requires.
+//
+// Edits are made with the usual session writes (PATCH for single cells).
+// The store applies them one at a time, and the stream reports them in that
+// order, so when two solvers write the same cell the later write wins for
+// everyone.
+func (h *Handler) SessionEvents(w http.ResponseWriter, r *http.Request) {
+	sid := chi.URLParam(r, "sid")
+
+	if _, err := h.store.Sessions.Get(sid); err != nil {
+		writeErr(w, r, http.StatusNotFound, codeSessionNotFound, "session not found")
+		return
+	}
+
+	q := r.URL.Query()
+	participant := strings.TrimSpace(q.Get("participant"))
+	if participant == "" {
+		participant = util.NewID()
+	}
+	events, token, leave := h.hub.Join(sid, realtime.Participant{ID: participant, Name: strings.TrimSpace(q.Get("name"))})
+	defer leave()
+
+	// Read the snapshot after joining, so no update falls between the two.
+	// Events queued meanwhile that the snapshot already covers have a
+	// version at or below it.
+	sess, err := h.store.Sessions.Get(sid)
+	if err != nil {
+		writeErr(w, r, http.StatusNotFound, codeSessionNotFound, "session not found")
+		return
+	}
+
+	// The stream outlives the server's write timeout.
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.Header().Set("X-Participant", participant)
+	w.Header().Set("X-Participant-Token", token)
+	w.WriteHeader(http.StatusOK)
+
+	if err := writeEvent(w, realtime.Snapshot(sess)); err != nil {
+		return
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case ev, ok := <-events:
+			if !ok {
+				// Dropped for falling behind; the client reconnects.
+				return
+			}
+			if err := writeEvent(w, ev); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// writeEvent writes one server-sent event. Events carrying a session
+// version use it as the event ID.
+func writeEvent(w http.ResponseWriter, ev realtime.Event) error {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	if ev.Version > 0 {
+		if _, err := fmt.Fprintf(w, "id: %d\n", ev.Version); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.Type, data)
+	return err
+}
+
+type cursorRequest struct {
+	Participant string           `json:"participant"`
+	Token       string           `json:"token"` // X-Participant-Token of one of the participant's event streams
+	Cell        string           `json:"cell"`  // "r,c"
+	Dir         domain.Direction `json:"dir,omitempty"`
+}
+
+// SetCursor moves a connected participant's cursor and broadcasts it to the
+// session. The request must carry the token of one of the participant's
+// open event streams, so solvers can't move each other's cursors. Cursors
+// aren't stored on the session.
+func (h *Handler) SetCursor(w http.ResponseWriter, r *http.Request) {
+	sid := chi.URLParam(r, "sid")
+
+	var req cursorRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, r, http.StatusBadRequest, codeInvalidJSON, "invalid json")
+		return
+	}
+
+	_, p, ok := h.sessionPuzzle(w, r, sid)
+	if !ok {
+		return
+	}
+
+	cr, err := domain.ParseCellKey(req.Cell)
+	if err != nil || !p.Grid.IsWhite(cr.R, cr.C) {
+		writeErr(w, r, http.StatusBadRequest, codeInvalidRequest, "cursor must be on a white cell")
+		return
+	}
+	if req.Dir != "" && req.Dir != domain.Across && req.Dir != domain.Down {
+		writeErr(w, r, http.StatusBadRequest, codeInvalidRequest, "dir must be across or down")
+		return
+	}
+
+	cursor := realtime.Cursor{Cell: domain.CellKey(cr.R, cr.C), Dir: req.Dir}
+	if !h.hub.SetCursor(sid, req.Participant, req.Token, cursor) {
+		writeErr(w, r, http.StatusConflict, codeParticipantNotConnected, "participant is not connected with that token")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
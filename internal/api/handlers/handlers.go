@@ -4,19 +4,28 @@ import (
 	"encoding/json"
 	"net/http"
 
+	"github.com/danny-molnar/crossword/internal/realtime"
 	"github.com/danny-molnar/crossword/internal/store"
 	"github.com/danny-molnar/crossword/internal/tools"
 )
 
 type Handler struct {
-	store *store.MemoryStore
-	wl    *tools.Wordlist
+	store      *store.MemoryStore
+	wl         *tools.Wordlist
+	creatorKey string
+
+	hub *realtime.Hub
 }
 
-func New(st *store.MemoryStore, wl *tools.Wordlist) *Handler {
+// New builds the handlers. creatorKey guards the creator routes (see
+// RequireCreator); leave it empty to disable them. hub carries session
+// events; it should be observing st's session updates.
+func New(st *store.MemoryStore, wl *tools.Wordlist, hub *realtime.Hub, creatorKey string) *Handler {
 	return &Handler{
-		store: st,
-		wl:    wl,
+		store:      st,
+		wl:         wl,
+		creatorKey: creatorKey,
+		hub:        hub,
 	}
 }
 
@@ -25,9 +34,3 @@ func writeJSON(w http.ResponseWriter, status int, v any) {
 	w.WriteHeader(status)
 	_ = json.NewEncoder(w).Encode(v)
 }
-
-func writeErr(w http.ResponseWriter, status int, msg string) {
-	writeJSON(w, status, map[string]string{
-		"error": msg,
-	})
-}
@@ -2,22 +2,126 @@ package handlers
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/go-chi/chi/v5"
 
 	"github.com/danny-molnar/crossword/internal/domain"
+	"github.com/danny-molnar/crossword/internal/store"
 )
 
+const (
+	defaultListLimit = 20
+	maxListLimit     = 100
+)
+
+type listPuzzlesResponse struct {
+	Items []domain.PuzzleSummary `json:"items"`
+	// NextCursor fetches the next page when passed as ?cursor; empty on the
+	// last page.
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// ListPuzzles lists puzzle summaries, most recently created first. Filters:
+// type, rows, cols, from and to (publication dates, YYYY-MM-DD, inclusive)
+// and author (substring). Paginate with limit and cursor.
+func (h *Handler) ListPuzzles(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	var f store.PuzzleFilter
+	f.Type = domain.PuzzleType(q.Get("type"))
+	f.Author = q.Get("author")
+
+	ints := []struct {
+		name string
+		dst  *int
+	}{{"rows", &f.Rows}, {"cols", &f.Cols}}
+	for _, p := range ints {
+		if v := q.Get(p.name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 {
+				writeErr(w, r, http.StatusBadRequest, codeInvalidParameter, "invalid "+p.name)
+				return
+			}
+			*p.dst = n
+		}
+	}
+
+	dates := []struct {
+		name string
+		dst  *domain.Date
+	}{{"from", &f.From}, {"to", &f.To}}
+	for _, d := range dates {
+		if v := q.Get(d.name); v != "" {
+			t, err := domain.ParseDate(v)
+			if err != nil {
+				writeErr(w, r, http.StatusBadRequest, codeInvalidParameter, "invalid "+d.name+" (want YYYY-MM-DD)")
+				return
+			}
+			*d.dst = t
+		}
+	}
+
+	limit := defaultListLimit
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > maxListLimit {
+			writeErr(w, r, http.StatusBadRequest, codeInvalidParameter, "invalid limit")
+			return
+		}
+		limit = n
+	}
+
+	puzzles, next, err := h.store.Puzzles.List(f, q.Get("cursor"), limit)
+	if err != nil {
+		writeErr(w, r, http.StatusBadRequest, codeInvalidParameter, "invalid cursor")
+		return
+	}
+	resp := listPuzzlesResponse{
+		Items:      make([]domain.PuzzleSummary, 0, len(puzzles)),
+		NextCursor: next,
+	}
+	for _, p := range puzzles {
+		resp.Items = append(resp.Items, domain.ToSummary(p))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
 func (h *Handler) GetPuzzle(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
-	p, err := h.store.Puzzles.GetPuzzle(id)
-	if err != nil {
-		writeErr(w, http.StatusNotFound, "puzzle not found")
+	p, ok := h.puzzleAtRevision(w, r, id)
+	if !ok {
 		return
 	}
 
 	// Always return the public view (no answers / solutions)
 	pub := domain.ToPublic(p)
+	setETag(w, p.Revision)
 	writeJSON(w, http.StatusOK, pub)
 }
+
+// puzzleAtRevision loads the latest revision of a puzzle, or the one named
+// by ?revision (sessions are pinned to the revision they started on). It
+// writes the error response and returns ok=false on failure.
+func (h *Handler) puzzleAtRevision(w http.ResponseWriter, r *http.Request, id string) (domain.Puzzle, bool) {
+	var (
+		p   domain.Puzzle
+		err error
+	)
+	if revStr := r.URL.Query().Get("revision"); revStr != "" {
+		rev, convErr := strconv.Atoi(revStr)
+		if convErr != nil || rev < 1 {
+			writeErr(w, r, http.StatusBadRequest, codeInvalidParameter, "invalid revision")
+			return domain.Puzzle{}, false
+		}
+		p, err = h.store.Puzzles.GetRevision(id, rev)
+	} else {
+		p, err = h.store.Puzzles.GetPuzzle(id)
+	}
+	if err != nil {
+		writeErr(w, r, http.StatusNotFound, codePuzzleNotFound, "puzzle not found")
+		return domain.Puzzle{}, false
+	}
+	return p, true
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/danny-molnar/crossword/internal/domain"
+)
+
+type revealSessionResponse struct {
+	// Revealed lists the cells whose solution this request wrote into the
+	// session, in scope order.
+	Revealed []string            `json:"revealed"`
+	Session  domain.SolveSession `json:"session"`
+}
+
+// RevealSession writes the solution of a cell, an entry or the whole grid
+// into the session's fill and marks those cells as revealed. Only cells in
+// the requested scope are touched, so no other solutions leave the server.
+// Each call counts as one reveal.
+func (h *Handler) RevealSession(w http.ResponseWriter, r *http.Request) {
+	sid := chi.URLParam(r, "sid")
+	want, ok := ifMatch(w, r)
+	if !ok {
+		return
+	}
+
+	_, p, ok := h.sessionPuzzle(w, r, sid)
+	if !ok {
+		return
+	}
+	cells, ok := decodeScope(w, r, p)
+	if !ok {
+		return
+	}
+
+	// Solutions may live on cells or only in entry answers.
+	solved, _ := domain.FillCellsFromAnswers(p)
+
+	resp := revealSessionResponse{Revealed: []string{}}
+	updated, ok := h.updateSession(w, r, sid, want, p, func(cur domain.SolveSession) domain.SolveSession {
+		grid := make(map[string]string, len(cur.GridState)+len(cells))
+		for k, v := range cur.GridState {
+			grid[k] = v
+		}
+		revealed := make(map[string]bool, len(cur.Revealed)+len(cells))
+		for k, v := range cur.Revealed {
+			revealed[k] = v
+		}
+		pencil := make(map[string]bool, len(cur.Pencil))
+		for k, v := range cur.Pencil {
+			pencil[k] = v
+		}
+
+		for _, cr := range cells {
+			cell := solved.Grid.Cells[cr.R][cr.C]
+			if cell.IsGiven || cell.Solution == "" {
+				continue
+			}
+			key := domain.CellKey(cr.R, cr.C)
+			grid[key] = domain.NormalizeAnswer(cell.Solution)
+			revealed[key] = true
+			delete(pencil, key)
+			resp.Revealed = append(resp.Revealed, key)
+		}
+
+		cur.GridState = grid
+		cur.Revealed = revealed
+		cur.Pencil = pencil
+		cur.RevealsUsed++
+		return cur
+	})
+	if !ok {
+		return
+	}
+
+	resp.Session = updated
+	writeJSON(w, http.StatusOK, resp)
+}
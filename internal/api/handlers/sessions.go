@@ -2,12 +2,17 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 
 	"github.com/danny-molnar/crossword/internal/domain"
+	"github.com/danny-molnar/crossword/internal/store"
 	"github.com/danny-molnar/crossword/internal/util"
 )
 
@@ -19,22 +24,29 @@ func (h *Handler) CreateSession(w http.ResponseWriter, r *http.Request) {
 	puzzleID := chi.URLParam(r, "id")
 
 	// Ensure puzzle exists
-	if _, err := h.store.Puzzles.GetPuzzle(puzzleID); err != nil {
-		writeErr(w, http.StatusNotFound, "puzzle not found")
+	p, err := h.store.Puzzles.GetPuzzle(puzzleID)
+	if err != nil {
+		writeErr(w, r, http.StatusNotFound, codePuzzleNotFound, "puzzle not found")
 		return
 	}
 
 	now := time.Now().UTC()
 	sess := domain.SolveSession{
-		ID:        util.NewID(),
-		PuzzleID:  puzzleID,
-		CreatedAt: now,
-		UpdatedAt: now,
-		GridState: map[string]string{},
-		Pencil:    map[string]bool{},
+		ID:             util.NewID(),
+		PuzzleID:       puzzleID,
+		PuzzleRevision: p.Revision,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+		GridState:      domain.ApplyGivens(p, nil),
+		Pencil:         map[string]bool{},
+		Checked:        map[string]bool{},
+		Revealed:       map[string]bool{},
+
+		TimerRunningSince: now,
 	}
 
-	h.store.Sessions.Create(sess)
+	sess = h.store.Sessions.Create(sess)
+	setETag(w, sess.Version)
 	writeJSON(w, http.StatusCreated, createSessionResponse{Session: sess})
 }
 
@@ -43,40 +55,339 @@ func (h *Handler) GetSession(w http.ResponseWriter, r *http.Request) {
 
 	sess, err := h.store.Sessions.Get(sid)
 	if err != nil {
-		writeErr(w, http.StatusNotFound, "session not found")
+		writeErr(w, r, http.StatusNotFound, codeSessionNotFound, "session not found")
 		return
 	}
 
+	setETag(w, sess.Version)
 	writeJSON(w, http.StatusOK, sess)
 }
 
+// sessionPuzzle loads a session and the puzzle revision it is pinned to,
+// writing a 404 and returning ok=false if either is missing.
+func (h *Handler) sessionPuzzle(w http.ResponseWriter, r *http.Request, sid string) (domain.SolveSession, domain.Puzzle, bool) {
+	sess, err := h.store.Sessions.Get(sid)
+	if err != nil {
+		writeErr(w, r, http.StatusNotFound, codeSessionNotFound, "session not found")
+		return domain.SolveSession{}, domain.Puzzle{}, false
+	}
+	p, err := h.store.Puzzles.GetRevision(sess.PuzzleID, sess.PuzzleRevision)
+	if err != nil {
+		writeErr(w, r, http.StatusNotFound, codePuzzleNotFound, "puzzle not found")
+		return domain.SolveSession{}, domain.Puzzle{}, false
+	}
+	return sess, p, true
+}
+
+// updateSession applies update to a session if want still matches it,
+// records completion against p (the puzzle revision the session ends up on)
+// and sets the new ETag. On failure it writes a 404, or a 412 with the
+// current session, and returns ok=false.
+func (h *Handler) updateSession(w http.ResponseWriter, r *http.Request, sid string, want precondition, p domain.Puzzle, update func(domain.SolveSession) domain.SolveSession) (domain.SolveSession, bool) {
+	sess, err := h.store.Sessions.Get(sid)
+	if err != nil {
+		writeErr(w, r, http.StatusNotFound, codeSessionNotFound, "session not found")
+		return domain.SolveSession{}, false
+	}
+	// Under If-Match the store checks the version again, so a write since
+	// Get is a 412.
+	updated, err := h.store.Sessions.UpdateIfVersion(sid, want.expect(sess.Version), func(cur domain.SolveSession) domain.SolveSession {
+		return domain.MarkCompleted(p, update(cur), time.Now().UTC())
+	})
+	switch {
+	case errors.Is(err, store.ErrVersionMismatch):
+		writeStale(w, r, updated.Version, updated)
+		return domain.SolveSession{}, false
+	case err != nil:
+		writeErr(w, r, http.StatusNotFound, codeSessionNotFound, "session not found")
+		return domain.SolveSession{}, false
+	}
+	setETag(w, updated.Version)
+	return updated, true
+}
+
 type updateSessionRequest struct {
 	GridState map[string]string `json:"gridState"`
 	Pencil    map[string]bool   `json:"pencil"`
 }
 
+// UpdateSession replaces a session's fill and/or pencil marks. Values are
+// checked like PatchSession's; given cells may only be sent with their given
+// letter. If any is invalid nothing is stored and the problems are returned
+// with a 422.
 func (h *Handler) UpdateSession(w http.ResponseWriter, r *http.Request) {
 	sid := chi.URLParam(r, "sid")
+	want, ok := ifMatch(w, r)
+	if !ok {
+		return
+	}
 
 	var req updateSessionRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeErr(w, http.StatusBadRequest, "invalid json")
+		writeErr(w, r, http.StatusBadRequest, codeInvalidJSON, "invalid json")
+		return
+	}
+
+	_, p, ok := h.sessionPuzzle(w, r, sid)
+	if !ok {
 		return
 	}
 
-	updated, err := h.store.Sessions.Update(sid, func(cur domain.SolveSession) domain.SolveSession {
+	// A session's GridState includes its given cells, so a client may send
+	// them back unchanged; they are restored by ApplyGivens below.
+	state := make(map[string]string, len(req.GridState))
+	for key, v := range req.GridState {
+		if cr, err := domain.ParseCellKey(key); err == nil && p.Grid.IsWhite(cr.R, cr.C) &&
+			p.Grid.Cells[cr.R][cr.C].IsGiven && domain.CellValueMatches(p.Grid.Cells[cr.R][cr.C].Solution, v) {
+			continue
+		}
+		state[key] = v
+	}
+	cells, pencil, verr := checkCellWrites(p, "gridState", state, req.Pencil)
+	if len(verr.Problems) > 0 {
+		writeValidation(w, r, verr)
+		return
+	}
+	for k, v := range cells {
+		if v == "" {
+			delete(cells, k)
+		}
+	}
+	for k, v := range pencil {
+		if !v {
+			delete(pencil, k)
+		}
+	}
+
+	updated, ok := h.updateSession(w, r, sid, want, p, func(cur domain.SolveSession) domain.SolveSession {
 		if req.GridState != nil {
-			cur.GridState = req.GridState
+			cur.GridState = domain.ApplyGivens(p, cells)
 		}
 		if req.Pencil != nil {
-			cur.Pencil = req.Pencil
+			cur.Pencil = pencil
 		}
 		return cur
 	})
-	if err != nil {
-		writeErr(w, http.StatusNotFound, "session not found")
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, updated)
+}
+
+type patchSessionRequest struct {
+	// Cells maps "r,c" to the new value; "" clears the cell.
+	Cells map[string]string `json:"cells"`
+	// Pencil maps "r,c" to whether the cell is pencilled.
+	Pencil map[string]bool `json:"pencil"`
+}
+
+// PatchSession applies per-cell changes to a session. Every change is
+// checked against the puzzle first (bounds, blocks, given cells and the
+// cell alphabet); if any is invalid nothing is applied and the problems are
+// returned with a 422.
+func (h *Handler) PatchSession(w http.ResponseWriter, r *http.Request) {
+	sid := chi.URLParam(r, "sid")
+	want, ok := ifMatch(w, r)
+	if !ok {
+		return
+	}
+
+	var req patchSessionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeErr(w, r, http.StatusBadRequest, codeInvalidJSON, "invalid json")
+		return
+	}
+
+	_, p, ok := h.sessionPuzzle(w, r, sid)
+	if !ok {
+		return
+	}
+
+	cells, pencil, verr := checkCellWrites(p, "cells", req.Cells, req.Pencil)
+	if len(verr.Problems) > 0 {
+		writeValidation(w, r, verr)
+		return
+	}
+
+	updated, ok := h.updateSession(w, r, sid, want, p, func(cur domain.SolveSession) domain.SolveSession {
+		grid := make(map[string]string, len(cur.GridState)+len(cells))
+		for k, v := range cur.GridState {
+			grid[k] = v
+		}
+		for k, v := range cells {
+			if v == "" {
+				delete(grid, k)
+			} else {
+				grid[k] = v
+			}
+		}
+
+		pen := make(map[string]bool, len(cur.Pencil)+len(pencil))
+		for k, v := range cur.Pencil {
+			pen[k] = v
+		}
+		for k, v := range pencil {
+			if v {
+				pen[k] = true
+			} else {
+				delete(pen, k)
+			}
+		}
+
+		cur.GridState = grid
+		cur.Pencil = pen
+		return cur
+	})
+	if !ok {
 		return
 	}
 
 	writeJSON(w, http.StatusOK, updated)
 }
+
+// checkCellWrites checks a request's cell values and pencil marks against
+// the puzzle (see PatchSession) and returns them with canonical keys and
+// normalised values. Problems are reported under cellsField["key"] and
+// pencil["key"], sorted by field.
+func checkCellWrites(p domain.Puzzle, cellsField string, reqCells map[string]string, reqPencil map[string]bool) (map[string]string, map[string]bool, domain.ValidationError) {
+	var verr domain.ValidationError
+	cells := make(map[string]string, len(reqCells))
+	for key, v := range reqCells {
+		field := fmt.Sprintf("%s[%q]", cellsField, key)
+		cr, err := domain.WritableCell(p, key)
+		if err != nil {
+			verr.Add(field, err.Error())
+			continue
+		}
+		norm, err := domain.NormalizeCellValue(v)
+		if err != nil {
+			verr.Add(field, fmt.Sprintf("cell %s: %v", key, err))
+			continue
+		}
+		// Canonical key, so " 1, 2" and "1,2" don't end up as two cells.
+		cells[domain.CellKey(cr.R, cr.C)] = norm
+	}
+	pencil := make(map[string]bool, len(reqPencil))
+	for key, v := range reqPencil {
+		cr, err := domain.WritableCell(p, key)
+		if err != nil {
+			verr.Add(fmt.Sprintf("pencil[%q]", key), err.Error())
+			continue
+		}
+		pencil[domain.CellKey(cr.R, cr.C)] = v
+	}
+	slices.SortFunc(verr.Problems, func(a, b domain.Problem) int {
+		return strings.Compare(a.Field, b.Field)
+	})
+	return cells, pencil, verr
+}
+
+// keepMarks returns the marks in m (pencil, checked or revealed) on cells of
+// p a solver can write to. With a fill, marks on cells it leaves empty are
+// dropped too.
+func keepMarks(p domain.Puzzle, m map[string]bool, fill map[string]string) map[string]bool {
+	out := map[string]bool{}
+	for key, v := range m {
+		if _, err := domain.WritableCell(p, key); err != nil {
+			continue
+		}
+		if fill != nil && fill[key] == "" {
+			continue
+		}
+		out[key] = v
+	}
+	return out
+}
+
+type migrateSessionRequest struct {
+	// Revision to move to; 0 means the latest.
+	Revision int `json:"revision"`
+	// Force applies the migration even if some cells conflict, dropping
+	// their values (given cells get their given letter).
+	Force bool `json:"force"`
+}
+
+type migrateSessionResponse struct {
+	// Error is set when the migration was refused because of conflicts.
+	Error     *apiError                  `json:"error,omitempty"`
+	Session   domain.SolveSession        `json:"session"`
+	Conflicts []domain.MigrationConflict `json:"conflicts"`
+}
+
+// MigrateSession moves a session onto another revision of its puzzle,
+// carrying its fill across. If any filled cells don't fit the new revision,
+// or sit in an entry whose shape changed, it responds 409 with the
+// conflicts and leaves the session alone, unless the request sets force.
+func (h *Handler) MigrateSession(w http.ResponseWriter, r *http.Request) {
+	sid := chi.URLParam(r, "sid")
+	want, ok := ifMatch(w, r)
+	if !ok {
+		return
+	}
+
+	var req migrateSessionRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeErr(w, r, http.StatusBadRequest, codeInvalidJSON, "invalid json")
+			return
+		}
+	}
+
+	sess, err := h.store.Sessions.Get(sid)
+	if err != nil {
+		writeErr(w, r, http.StatusNotFound, codeSessionNotFound, "session not found")
+		return
+	}
+	if !want.matches(sess.Version) {
+		writeStale(w, r, sess.Version, sess)
+		return
+	}
+
+	var to domain.Puzzle
+	if req.Revision > 0 {
+		to, err = h.store.Puzzles.GetRevision(sess.PuzzleID, req.Revision)
+	} else {
+		to, err = h.store.Puzzles.GetPuzzle(sess.PuzzleID)
+	}
+	if err != nil {
+		writeErr(w, r, http.StatusNotFound, codeRevisionNotFound, "puzzle revision not found")
+		return
+	}
+
+	from, err := h.store.Puzzles.GetRevision(sess.PuzzleID, sess.PuzzleRevision)
+	if err != nil {
+		writeErr(w, r, http.StatusNotFound, codeRevisionNotFound, "session's puzzle revision not found")
+		return
+	}
+
+	if _, conflicts := domain.MigrateFill(from, to, sess.GridState); len(conflicts) > 0 && !req.Force {
+		setETag(w, sess.Version)
+		e := newError(r, codeMigrationConflict, "filled cells don't fit the new revision")
+		writeJSON(w, http.StatusConflict, migrateSessionResponse{Error: &e, Session: sess, Conflicts: conflicts})
+		return
+	}
+
+	// Migrate the fill as it stands under the store lock, so edits made
+	// since the check above aren't lost.
+	conflicts := []domain.MigrationConflict{}
+	updated, ok := h.updateSession(w, r, sid, want, to, func(cur domain.SolveSession) domain.SolveSession {
+		if cur.PuzzleRevision != from.Revision {
+			// Migrated meanwhile; revisions are never removed.
+			from, _ = h.store.Puzzles.GetRevision(cur.PuzzleID, cur.PuzzleRevision)
+		}
+		fill, c := domain.MigrateFill(from, to, cur.GridState)
+		conflicts = append(conflicts, c...)
+		cur.PuzzleRevision = to.Revision
+		cur.GridState = fill
+		cur.Pencil = keepMarks(to, cur.Pencil, nil)
+		cur.Checked = keepMarks(to, cur.Checked, fill)
+		cur.Revealed = keepMarks(to, cur.Revealed, fill)
+		return cur
+	})
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, migrateSessionResponse{Session: updated, Conflicts: conflicts})
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/danny-molnar/crossword/internal/domain"
+)
+
+// PauseSession stops a session's solve timer. Pausing a paused session is
+// a no-op.
+func (h *Handler) PauseSession(w http.ResponseWriter, r *http.Request) {
+	h.setTimer(w, r, domain.PauseTimer)
+}
+
+// ResumeSession starts a paused session's solve timer again. A completed
+// session's timer stays stopped.
+func (h *Handler) ResumeSession(w http.ResponseWriter, r *http.Request) {
+	h.setTimer(w, r, domain.ResumeTimer)
+}
+
+// setTimer applies a timer change to a session. A completed session's time
+// is final, so both pause and resume answer 409 for it.
+func (h *Handler) setTimer(w http.ResponseWriter, r *http.Request, set func(domain.SolveSession, time.Time) domain.SolveSession) {
+	sid := chi.URLParam(r, "sid")
+	want, ok := ifMatch(w, r)
+	if !ok {
+		return
+	}
+
+	sess, p, ok := h.sessionPuzzle(w, r, sid)
+	if !ok {
+		return
+	}
+	if !sess.CompletedAt.IsZero() {
+		writeErr(w, r, http.StatusConflict, codeSessionCompleted, "session is completed")
+		return
+	}
+
+	updated, ok := h.updateSession(w, r, sid, want, p, func(cur domain.SolveSession) domain.SolveSession {
+		return set(cur, time.Now().UTC())
+	})
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, updated)
+}
@@ -13,7 +13,7 @@ func (h *Handler) Anagram(w http.ResponseWriter, r *http.Request) {
 	if lenStr != "" {
 		n, err := strconv.Atoi(lenStr)
 		if err != nil || n < 0 {
-			writeErr(w, http.StatusBadRequest, "invalid len")
+			writeErr(w, r, http.StatusBadRequest, codeInvalidParameter, "invalid len")
 			return
 		}
 		length = n
@@ -21,7 +21,7 @@ func (h *Handler) Anagram(w http.ResponseWriter, r *http.Request) {
 
 	res, err := h.wl.Anagrams(letters, length)
 	if err != nil {
-		writeErr(w, http.StatusBadRequest, err.Error())
+		writeErr(w, r, http.StatusBadRequest, codeInvalidParameter, err.Error())
 		return
 	}
 
@@ -36,7 +36,7 @@ func (h *Handler) Pattern(w http.ResponseWriter, r *http.Request) {
 	if lenStr != "" {
 		n, err := strconv.Atoi(lenStr)
 		if err != nil || n < 0 {
-			writeErr(w, http.StatusBadRequest, "invalid len")
+			writeErr(w, r, http.StatusBadRequest, codeInvalidParameter, "invalid len")
 			return
 		}
 		length = n
@@ -44,7 +44,7 @@ func (h *Handler) Pattern(w http.ResponseWriter, r *http.Request) {
 
 	res, err := h.wl.PatternMatch(pattern, length)
 	if err != nil {
-		writeErr(w, http.StatusBadRequest, err.Error())
+		writeErr(w, r, http.StatusBadRequest, codeInvalidParameter, err.Error())
 		return
 	}
 
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/danny-molnar/crossword/internal/api/handlers"
+	"github.com/danny-molnar/crossword/internal/openapi"
+)
+
+// buildOpenAPI describes every route registered on r. It also returns the
+// routes handlers.Spec has no description for, and descriptions that match
+// no route.
+func buildOpenAPI(r chi.Routes) (openapi.Document, []openapi.Route, []string, error) {
+	var routes []openapi.Route
+	err := chi.Walk(r, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		routes = append(routes, openapi.Route{Method: method, Pattern: route})
+		return nil
+	})
+	if err != nil {
+		return openapi.Document{}, nil, nil, err
+	}
+	doc, undocumented, unrouted := handlers.Spec().Build(routes)
+	return doc, undocumented, unrouted, nil
+}
+
+// serveOpenAPI serves the OpenAPI document for r, built on first request
+// once every route is registered. fail answers if it can't be built.
+func serveOpenAPI(r chi.Routes, fail http.HandlerFunc) http.HandlerFunc {
+	var (
+		once sync.Once
+		body []byte
+		err  error
+	)
+	return func(w http.ResponseWriter, req *http.Request) {
+		once.Do(func() {
+			var doc openapi.Document
+			if doc, _, _, err = buildOpenAPI(r); err == nil {
+				body, err = json.Marshal(doc)
+			}
+		})
+		if err != nil {
+			fail(w, req)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(body)
+	}
+}
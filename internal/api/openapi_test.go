@@ -0,0 +1,85 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/danny-molnar/crossword/internal/realtime"
+	"github.com/danny-molnar/crossword/internal/store"
+	"github.com/danny-molnar/crossword/internal/tools"
+)
+
+func TestOpenAPI_EveryRouteDocumented(t *testing.T) {
+	r := NewRouter(store.NewMemoryStore(), realtime.NewHub(), &tools.Wordlist{}, Config{})
+
+	doc, undocumented, unrouted, err := buildOpenAPI(r.(chi.Routes))
+	if err != nil {
+		t.Fatalf("buildOpenAPI: %v", err)
+	}
+	for _, rt := range undocumented {
+		t.Errorf("route %s has no schema; add it to routeDocs in internal/api/handlers/docs.go", rt)
+	}
+	for _, key := range unrouted {
+		t.Errorf("routeDocs has %s, which is not a route", key)
+	}
+
+	// Schemas come from the handler types.
+	for _, name := range []string{"PuzzlePublic", "SolveSession", "PatchSessionRequest", "ErrorResponse"} {
+		if doc.Components.Schemas[name] == nil {
+			t.Errorf("no %s schema", name)
+		}
+	}
+}
+
+func TestOpenAPI_Served(t *testing.T) {
+	r := NewRouter(store.NewMemoryStore(), realtime.NewHub(), &tools.Wordlist{}, Config{})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/openapi.json", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status=%d", rec.Code)
+	}
+
+	var doc struct {
+		OpenAPI string                     `json:"openapi"`
+		Paths   map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+	if doc.OpenAPI == "" || doc.Paths["/v1/sessions/{sid}"] == nil {
+		t.Fatalf("unexpected document: %s", rec.Body.String())
+	}
+}
+
+func TestOpenAPI_ErrorResponses(t *testing.T) {
+	r := NewRouter(store.NewMemoryStore(), realtime.NewHub(), &tools.Wordlist{}, Config{})
+
+	doc, _, _, err := buildOpenAPI(r.(chi.Routes))
+	if err != nil {
+		t.Fatalf("buildOpenAPI: %v", err)
+	}
+
+	schemaOf := func(path, method, status string) string {
+		resp, ok := doc.Paths[path][method].Responses[status]
+		if !ok {
+			t.Fatalf("%s %s has no %s response", method, path, status)
+		}
+		return resp.Content["application/json"].Schema.Ref
+	}
+	for _, tt := range []struct{ path, method, status, want string }{
+		{"/v1/sessions/{sid}/migrate", "post", "409", "#/components/schemas/MigrateSessionResponse"},
+		{"/v1/sessions/{sid}/migrate", "post", "412", "#/components/schemas/StaleSession"},
+		{"/v1/sessions/{sid}", "patch", "412", "#/components/schemas/StaleSession"},
+		{"/v1/puzzles/{id}", "put", "412", "#/components/schemas/StalePuzzle"},
+		{"/v1/sessions/{sid}", "patch", "default", "#/components/schemas/ErrorResponse"},
+	} {
+		if got := schemaOf(tt.path, tt.method, tt.status); got != tt.want {
+			t.Errorf("%s %s %s schema = %q, want %q", tt.method, tt.path, tt.status, got, tt.want)
+		}
+	}
+}
@@ -7,30 +7,62 @@ import (
 	"github.com/go-chi/chi/v5/middleware"
 
 	"github.com/danny-molnar/crossword/internal/api/handlers"
+	"github.com/danny-molnar/crossword/internal/realtime"
 	"github.com/danny-molnar/crossword/internal/store"
 	"github.com/danny-molnar/crossword/internal/tools"
 )
 
-func NewRouter(st *store.MemoryStore, wl *tools.Wordlist) http.Handler {
+// Config holds router settings that come from the server's configuration.
+type Config struct {
+	// CreatorKey is the bearer token for the creator routes. Empty disables
+	// them.
+	CreatorKey string
+}
+
+// NewRouter builds the API's routes. hub delivers session events; register
+// it as an observer of st's session updates (once, where st is built) so
+// edits reach connected solvers.
+func NewRouter(st *store.MemoryStore, hub *realtime.Hub, wl *tools.Wordlist, cfg Config) http.Handler {
 	r := chi.NewRouter()
+	root := r
 	r.Use(middleware.RequestID)
 	r.Use(middleware.RealIP)
 	r.Use(middleware.Logger)
-	r.Use(middleware.Recoverer)
 
-	h := handlers.New(st, wl)
+	h := handlers.New(st, wl, hub, cfg.CreatorKey)
+	r.Use(h.Recover)
+	r.NotFound(h.NotFound)
+	r.MethodNotAllowed(h.MethodNotAllowed)
 
 	r.Route("/v1", func(r chi.Router) {
 		r.Get("/health", func(w http.ResponseWriter, _ *http.Request) {
 			w.WriteHeader(http.StatusOK)
 			_, _ = w.Write([]byte("ok"))
 		})
+		r.Get("/openapi.json", serveOpenAPI(root, h.InternalError))
 
+		r.Get("/puzzles", h.ListPuzzles)
 		r.Get("/puzzles/{id}", h.GetPuzzle)
 
+		// Creator routes: full puzzles, answers included.
+		r.Group(func(r chi.Router) {
+			r.Use(h.RequireCreator)
+			r.Post("/puzzles", h.CreatePuzzle)
+			r.Put("/puzzles/{id}", h.UpdatePuzzle)
+			r.Get("/puzzles/{id}/full", h.GetPuzzleFull)
+		})
+
 		r.Post("/puzzles/{id}/sessions", h.CreateSession)
 		r.Get("/sessions/{sid}", h.GetSession)
 		r.Put("/sessions/{sid}", h.UpdateSession)
+		r.Patch("/sessions/{sid}", h.PatchSession)
+		r.Post("/sessions/{sid}/migrate", h.MigrateSession)
+		r.Post("/sessions/{sid}/check", h.CheckSession)
+		r.Post("/sessions/{sid}/reveal", h.RevealSession)
+		r.Post("/sessions/{sid}/pause", h.PauseSession)
+		r.Post("/sessions/{sid}/resume", h.ResumeSession)
+		r.Get("/sessions/{sid}/events", h.SessionEvents)
+		r.Post("/sessions/{sid}/cursor", h.SetCursor)
 
 		r.Get("/tools/anagram", h.Anagram)
 		r.Get("/tools/pattern", h.Pattern)
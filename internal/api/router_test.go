@@ -0,0 +1,381 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/danny-molnar/crossword/internal/api/handlers"
+	"github.com/danny-molnar/crossword/internal/domain"
+	"github.com/danny-molnar/crossword/internal/realtime"
+	"github.com/danny-molnar/crossword/internal/store"
+	"github.com/danny-molnar/crossword/internal/tools"
+)
+
+// newTestServer returns a router over a store holding one 3x3 puzzle, "p1",
+// with a block in the middle and the top-left C given; its entries are 1a,
+// 1d, 2d and 3a:
+//
+//	C A T
+//	O # O
+//	W E T
+func newTestServer(t *testing.T) http.Handler {
+	t.Helper()
+	rows := []string{"CAT", "O#O", "WET"}
+	g := domain.Grid{Rows: 3, Cols: 3}
+	for r, row := range rows {
+		var cells []domain.Cell
+		for c, ch := range row {
+			cell := domain.Cell{R: r, C: c}
+			if ch == '#' {
+				cell.IsBlock = true
+			} else {
+				cell.Solution = string(ch)
+			}
+			cells = append(cells, cell)
+		}
+		g.Cells = append(g.Cells, cells)
+	}
+	g.Cells[0][0].IsGiven = true
+
+	p := domain.Puzzle{ID: "p1", Title: "Test", Type: domain.PuzzleQuick, Rows: 3, Cols: 3, Grid: g, Entries: domain.GenerateEntries(g)}
+	if err := domain.ValidatePuzzle(p); err != nil {
+		t.Fatalf("fixture: %v", err)
+	}
+	st := store.NewMemoryStore()
+	st.Puzzles.PutPuzzle(p)
+	hub := realtime.NewHub()
+	st.Sessions.OnUpdate(hub.SessionUpdated)
+	return NewRouter(st, hub, &tools.Wordlist{}, Config{CreatorKey: testCreatorKey})
+}
+
+const testCreatorKey = "s3cret"
+
+func do(t *testing.T, h http.Handler, method, path, ifMatch, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	var rd io.Reader
+	if body != "" {
+		rd = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, path, rd)
+	if ifMatch != "" {
+		req.Header.Set("If-Match", ifMatch)
+	}
+	return send(h, req)
+}
+
+func send(h http.Handler, req *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+// newSession starts a session on p1 and returns its ID.
+func newSession(t *testing.T, h http.Handler) string {
+	t.Helper()
+	rec := do(t, h, http.MethodPost, "/v1/puzzles/p1/sessions", "", "")
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create session: status=%d body=%s", rec.Code, rec.Body)
+	}
+	var resp struct {
+		Session domain.SolveSession `json:"session"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || resp.Session.ID == "" {
+		t.Fatalf("create session: %v %s", err, rec.Body)
+	}
+	return resp.Session.ID
+}
+
+type errorBody struct {
+	Error struct {
+		Code      string `json:"code"`
+		RequestID string `json:"requestId"`
+		Problems  []struct {
+			Field string `json:"field"`
+		} `json:"problems"`
+	} `json:"error"`
+}
+
+func decodeError(t *testing.T, rec *httptest.ResponseRecorder) errorBody {
+	t.Helper()
+	var body errorBody
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid error body: %v %s", err, rec.Body)
+	}
+	return body
+}
+
+func TestRouter_Errors(t *testing.T) {
+	h := newTestServer(t)
+	rec := do(t, h, http.MethodGet, "/v1/nope", "", "")
+	if body := decodeError(t, rec); rec.Code != http.StatusNotFound || body.Error.Code != "not_found" || body.Error.RequestID == "" {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body)
+	}
+
+	// A panicking handler still answers in the envelope.
+	hd := handlers.New(store.NewMemoryStore(), &tools.Wordlist{}, realtime.NewHub(), "")
+	panicky := middleware.RequestID(hd.Recover(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic("boom")
+	})))
+	rec = do(t, panicky, http.MethodGet, "/", "", "")
+	if body := decodeError(t, rec); rec.Code != http.StatusInternalServerError || body.Error.Code != "internal_error" || body.Error.RequestID == "" {
+		t.Fatalf("panic: status=%d body=%s", rec.Code, rec.Body)
+	}
+}
+
+func TestSession_StaleIfMatch(t *testing.T) {
+	h := newTestServer(t)
+	sid := newSession(t, h)
+
+	rec := do(t, h, http.MethodPatch, "/v1/sessions/"+sid, `"1"`, `{"cells": {"0,1": "a"}}`)
+	if rec.Code != http.StatusOK || rec.Header().Get("ETag") != `"2"` {
+		t.Fatalf("patch: status=%d etag=%s body=%s", rec.Code, rec.Header().Get("ETag"), rec.Body)
+	}
+
+	// Still writing against version 1.
+	rec = do(t, h, http.MethodPatch, "/v1/sessions/"+sid, `"1"`, `{"cells": {"0,2": "T"}}`)
+	if rec.Code != http.StatusPreconditionFailed {
+		t.Fatalf("stale patch: status=%d body=%s", rec.Code, rec.Body)
+	}
+	if rec.Header().Get("ETag") != `"2"` {
+		t.Fatalf("stale patch: ETag=%s, want the current version", rec.Header().Get("ETag"))
+	}
+	var stale struct {
+		Error struct {
+			Code string `json:"code"`
+		} `json:"error"`
+		Current domain.SolveSession `json:"current"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &stale); err != nil {
+		t.Fatalf("invalid body: %v", err)
+	}
+	if stale.Error.Code != "version_mismatch" || stale.Current.Version != 2 || stale.Current.GridState["0,1"] != "A" || stale.Current.GridState["0,2"] != "" {
+		t.Fatalf("stale patch body = %s", rec.Body)
+	}
+}
+
+func TestSession_IfMatchForms(t *testing.T) {
+	h := newTestServer(t)
+	sid := newSession(t, h)
+
+	// If-Match compares strongly, so a weak tag never matches.
+	rec := do(t, h, http.MethodPatch, "/v1/sessions/"+sid, `W/"1"`, `{"cells": {"0,1": "A"}}`)
+	if rec.Code != http.StatusPreconditionFailed || rec.Header().Get("ETag") != `"1"` || !strings.Contains(rec.Body.String(), `"current":{`) {
+		t.Fatalf("weak: status=%d etag=%s body=%s", rec.Code, rec.Header().Get("ETag"), rec.Body)
+	}
+
+	// A list matches if any strong tag in it does.
+	for _, tc := range []struct{ header, etag string }{
+		{`"7", "1"`, `"2"`},
+		{`"x", W/"9", "2"`, `"3"`},
+		{`*`, `"4"`},
+	} {
+		rec := do(t, h, http.MethodPatch, "/v1/sessions/"+sid, tc.header, `{"cells": {"0,1": "A"}}`)
+		if rec.Code != http.StatusOK || rec.Header().Get("ETag") != tc.etag {
+			t.Fatalf("%s: status=%d etag=%s, want %s", tc.header, rec.Code, rec.Header().Get("ETag"), tc.etag)
+		}
+	}
+
+	for _, header := range []string{`1`, `"1`, `"1", *`, `W/1`} {
+		rec := do(t, h, http.MethodPatch, "/v1/sessions/"+sid, header, `{"cells": {"0,1": "A"}}`)
+		if rec.Code != http.StatusBadRequest || decodeError(t, rec).Error.Code != "invalid_parameter" {
+			t.Fatalf("%s: status=%d body=%s", header, rec.Code, rec.Body)
+		}
+	}
+}
+
+func TestSession_PatchRejectsBadCells(t *testing.T) {
+	h := newTestServer(t)
+	sid := newSession(t, h)
+
+	rec := do(t, h, http.MethodPatch, "/v1/sessions/"+sid, "", `{"cells": {"0,1": "A", "3,0": "X", "1,1": "X"}, "pencil": {"0,0": true}}`)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body)
+	}
+	body := decodeError(t, rec)
+	var fields []string
+	for _, p := range body.Error.Problems {
+		fields = append(fields, p.Field)
+	}
+	if body.Error.Code != "validation_failed" || strings.Join(fields, " ") != `cells["1,1"] cells["3,0"] pencil["0,0"]` {
+		t.Fatalf("body = %s", rec.Body)
+	}
+
+	// Nothing was applied, not even the valid cell.
+	rec = do(t, h, http.MethodGet, "/v1/sessions/"+sid, "", "")
+	if rec.Header().Get("ETag") != `"1"` || strings.Contains(rec.Body.String(), `"0,1"`) {
+		t.Fatalf("session changed: etag=%s body=%s", rec.Header().Get("ETag"), rec.Body)
+	}
+}
+
+func TestSession_PutValidates(t *testing.T) {
+	h := newTestServer(t)
+	sid := newSession(t, h)
+
+	rec := do(t, h, http.MethodPut, "/v1/sessions/"+sid, "", `{"gridState": {"0,0": "X", "0,9": "A", "0,1": "TOOMANYLETTERS"}}`)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body)
+	}
+	if n := len(decodeError(t, rec).Error.Problems); n != 3 {
+		t.Fatalf("%d problems, want 3: %s", n, rec.Body)
+	}
+
+	// The state a GET returns, given cell included, can be sent back.
+	rec = do(t, h, http.MethodPut, "/v1/sessions/"+sid, "", `{"gridState": {"0,0": "C", "0,1": "a"}}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body)
+	}
+	var sess domain.SolveSession
+	if err := json.Unmarshal(rec.Body.Bytes(), &sess); err != nil {
+		t.Fatal(err)
+	}
+	if len(sess.GridState) != 2 || sess.GridState["0,0"] != "C" || sess.GridState["0,1"] != "A" {
+		t.Fatalf("gridState = %v", sess.GridState)
+	}
+}
+
+// fill patches cells into a session, failing the test if that doesn't work.
+func fill(t *testing.T, h http.Handler, sid, cells string) {
+	t.Helper()
+	if rec := do(t, h, http.MethodPatch, "/v1/sessions/"+sid, "", `{"cells": `+cells+`}`); rec.Code != http.StatusOK {
+		t.Fatalf("fill: status=%d body=%s", rec.Code, rec.Body)
+	}
+}
+
+func TestSession_Check(t *testing.T) {
+	h := newTestServer(t)
+	sid := newSession(t, h)
+	// 0,1 right, 0,2 wrong (T), 2,0 wrong (W) but outside 1a.
+	fill(t, h, sid, `{"0,1": "A", "0,2": "X", "2,0": "Q"}`)
+
+	rec := do(t, h, http.MethodPost, "/v1/sessions/"+sid+"/check", "", `{"scope": "entry", "entryId": "1a"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status=%d body=%s", rec.Code, rec.Body)
+	}
+	var resp struct {
+		Incorrect []string            `json:"incorrect"`
+		Checked   []string            `json:"checked"`
+		Session   domain.SolveSession `json:"session"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	// The given 0,0 isn't checked; 2,0 is out of scope.
+	if strings.Join(resp.Incorrect, " ") != "0,2" || strings.Join(resp.Checked, " ") != "0,1 0,2" {
+		t.Fatalf("incorrect=%v checked=%v", resp.Incorrect, resp.Checked)
+	}
+	if len(resp.Session.Checked) != 2 || !resp.Session.Checked["0,1"] || !resp.Session.Checked["0,2"] || resp.Session.ChecksUsed != 1 {
+		t.Fatalf("session checked=%v checksUsed=%d", resp.Session.Checked, resp.Session.ChecksUsed)
+	}
+	// Wrong cells keep the solver's letter; the solutions never appear.
+	if resp.Session.GridState["0,2"] != "X" || strings.Contains(rec.Body.String(), `"T"`) || strings.Contains(rec.Body.String(), `"W"`) {
+		t.Fatalf("check leaked a solution: %s", rec.Body)
+	}
+
+	rec = do(t, h, http.MethodPost, "/v1/sessions/"+sid+"/check", "", `{"scope": "grid"}`)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"incorrect":["0,2","2,0"]`) || !strings.Contains(rec.Body.String(), `"checksUsed":2`) {
+		t.Fatalf("grid check: status=%d body=%s", rec.Code, rec.Body)
+	}
+
+	for _, body := range []string{`{"scope": "row"}`, `{"scope": "entry", "entryId": "9a"}`, `{"scope": "cell", "cell": "1,1"}`} {
+		rec := do(t, h, http.MethodPost, "/v1/sessions/"+sid+"/check", "", body)
+		if rec.Code != http.StatusBadRequest || decodeError(t, rec).Error.Code != "invalid_request" {
+			t.Fatalf("%s: status=%d body=%s", body, rec.Code, rec.Body)
+		}
+	}
+}
+
+func TestSession_RevealOnlyInScope(t *testing.T) {
+	h := newTestServer(t)
+	sid := newSession(t, h)
+	fill(t, h, sid, `{"0,2": "X", "2,0": "Q"}`)
+
+	type revealResponse struct {
+		Revealed []string            `json:"revealed"`
+		Session  domain.SolveSession `json:"session"`
+	}
+	reveal := func(body string) revealResponse {
+		t.Helper()
+		rec := do(t, h, http.MethodPost, "/v1/sessions/"+sid+"/reveal", "", body)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("reveal %s: status=%d body=%s", body, rec.Code, rec.Body)
+		}
+		var resp revealResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	resp := reveal(`{"scope": "cell", "cell": "0,2"}`)
+	want := map[string]string{"0,0": "C", "0,2": "T", "2,0": "Q"}
+	if strings.Join(resp.Revealed, " ") != "0,2" || len(resp.Session.Revealed) != 1 || !resp.Session.Revealed["0,2"] ||
+		!maps.Equal(resp.Session.GridState, want) || resp.Session.RevealsUsed != 1 {
+		t.Fatalf("cell reveal = %+v", resp)
+	}
+
+	// 2d runs down the right-hand column.
+	resp = reveal(`{"scope": "entry", "entryId": "2d"}`)
+	want = map[string]string{"0,0": "C", "0,2": "T", "1,2": "O", "2,0": "Q", "2,2": "T"}
+	if strings.Join(resp.Revealed, " ") != "0,2 1,2 2,2" || len(resp.Session.Revealed) != 3 ||
+		!maps.Equal(resp.Session.GridState, want) || resp.Session.RevealsUsed != 2 {
+		t.Fatalf("entry reveal = %+v", resp)
+	}
+}
+
+func TestSession_MigrateDropsMarks(t *testing.T) {
+	h := newTestServer(t)
+	sid := newSession(t, h)
+	rec := do(t, h, http.MethodPatch, "/v1/sessions/"+sid, "", `{"cells": {"0,2": "X", "1,0": "X", "2,2": "T"}, "pencil": {"0,2": true, "2,2": true}}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("patch: status=%d body=%s", rec.Code, rec.Body)
+	}
+	for _, path := range []string{"/check", "/reveal"} {
+		body := `{"scope": "grid"}`
+		if path == "/reveal" {
+			body = `{"scope": "cell", "cell": "0,1"}`
+		}
+		if rec := do(t, h, http.MethodPost, "/v1/sessions/"+sid+path, "", body); rec.Code != http.StatusOK {
+			t.Fatalf("%s: status=%d body=%s", path, rec.Code, rec.Body)
+		}
+	}
+
+	// Revision 2 gives 0,2 away and blocks 2,2.
+	var p domain.Puzzle
+	if err := json.Unmarshal(creator(t, h, http.MethodGet, "/v1/puzzles/p1/full", "", "").Body.Bytes(), &p); err != nil {
+		t.Fatal(err)
+	}
+	p.Grid.Cells[0][2].IsGiven = true
+	p.Grid.Cells[2][2] = domain.Cell{R: 2, C: 2, IsBlock: true}
+	p.Entries = nil
+	body, _ := json.Marshal(p)
+	if rec := creator(t, h, http.MethodPut, "/v1/puzzles/p1", "", string(body)); rec.Code != http.StatusOK {
+		t.Fatalf("revise: status=%d body=%s", rec.Code, rec.Body)
+	}
+
+	rec = do(t, h, http.MethodPost, "/v1/sessions/"+sid+"/migrate", "", `{"force": true}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("migrate: status=%d body=%s", rec.Code, rec.Body)
+	}
+	var resp struct {
+		Session domain.SolveSession `json:"session"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	s := resp.Session
+	// The given 0,2 takes its letter; nothing else remembers 0,2 or 2,2.
+	want := map[string]string{"0,0": "C", "0,1": "A", "0,2": "T", "1,0": "X"}
+	if s.PuzzleRevision != 2 || !maps.Equal(s.GridState, want) {
+		t.Fatalf("revision=%d gridState=%v", s.PuzzleRevision, s.GridState)
+	}
+	if len(s.Pencil) != 0 || !maps.Equal(s.Checked, map[string]bool{"1,0": true}) || !maps.Equal(s.Revealed, map[string]bool{"0,1": true}) {
+		t.Fatalf("pencil=%v checked=%v revealed=%v", s.Pencil, s.Checked, s.Revealed)
+	}
+}
@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+type AnnotationShape string
+
+const (
+	ShapeNone   AnnotationShape = ""
+	ShapeCircle AnnotationShape = "circle"
+	ShapeSquare AnnotationShape = "square"
+)
+
+// maxAnnotationLabel is the longest label (in runes) that still fits in a
+// corner of a cell.
+const maxAnnotationLabel = 8
+
+// CellAnnotation marks a cell in a themed puzzle: a circled square, a shaded
+// one, or both. Fill is a hex colour ("#rgb" or "#rrggbb"); a fill with no
+// shape shades the whole cell.
+type CellAnnotation struct {
+	Shape AnnotationShape `json:"shape,omitempty"`
+	Fill  string          `json:"fill,omitempty"`
+	Label string          `json:"label,omitempty"`
+}
+
+func validateAnnotation(verr *ValidationError, r, c int, a CellAnnotation) {
+	switch a.Shape {
+	case ShapeNone, ShapeCircle, ShapeSquare:
+	default:
+		verr.add(annotationField(r, c, "shape"), "cell [%d,%d] annotation has unknown shape %q", r, c, a.Shape)
+	}
+	if a.Fill != "" && !isHexColour(a.Fill) {
+		verr.add(annotationField(r, c, "fill"), "cell [%d,%d] annotation fill %q is not a hex colour", r, c, a.Fill)
+	}
+	if n := utf8.RuneCountInString(a.Label); n > maxAnnotationLabel {
+		verr.add(annotationField(r, c, "label"), "cell [%d,%d] annotation label is %d characters, max %d", r, c, n, maxAnnotationLabel)
+	}
+	for _, ch := range a.Label {
+		if unicode.IsControl(ch) {
+			verr.add(annotationField(r, c, "label"), "cell [%d,%d] annotation label contains control characters", r, c)
+			break
+		}
+	}
+	if a.Shape == ShapeNone && a.Fill == "" && a.Label == "" {
+		verr.add(annotationField(r, c, ""), "cell [%d,%d] annotation is empty", r, c)
+	}
+}
+
+func isHexColour(s string) bool {
+	if len(s) != 4 && len(s) != 7 || s[0] != '#' {
+		return false
+	}
+	for i := 1; i < len(s); i++ {
+		ch := s[i]
+		if !('0' <= ch && ch <= '9' || 'a' <= ch && ch <= 'f' || 'A' <= ch && ch <= 'F') {
+			return false
+		}
+	}
+	return true
+}
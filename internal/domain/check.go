@@ -0,0 +1,127 @@
+package domain
+
+// CellStatus is the result of checking one cell of a fill.
+type CellStatus string
+
+const (
+	CellEmpty     CellStatus = "empty"
+	CellCorrect   CellStatus = "correct"
+	CellIncorrect CellStatus = "incorrect"
+	CellGiven     CellStatus = "given"
+	// CellUnknown: the puzzle has no solution for this cell, so a filled
+	// value can't be judged.
+	CellUnknown CellStatus = "unknown"
+	// CellRevealed: the solver asked for the answer. Revealed cells count as
+	// filled but are kept apart from correct ones so they can be left out of
+	// scoring.
+	CellRevealed CellStatus = "revealed"
+)
+
+// FillStatus summarises a group of cells: an entry or the whole grid.
+type FillStatus string
+
+const (
+	FillEmpty      FillStatus = "empty"      // nothing filled in (givens aside)
+	FillIncomplete FillStatus = "incomplete" // some cells empty, none wrong
+	FillIncorrect  FillStatus = "incorrect"  // at least one wrong cell
+	FillCorrect    FillStatus = "correct"    // every cell filled, none wrong
+)
+
+type CheckResult struct {
+	// Cells is keyed like SolveSession.GridState ("r,c") and covers every
+	// white cell of the grid.
+	Cells map[string]CellStatus `json:"cells"`
+	// Entries is keyed by entry ID.
+	Entries map[string]FillStatus `json:"entries"`
+	Grid    FillStatus            `json:"grid"`
+}
+
+// CheckFill compares a fill (keyed like SolveSession.GridState) with the
+// puzzle's solutions. Cell solutions are used where present; otherwise they
+// are derived from entry answers. Values are compared with CellValueMatches,
+// so case and punctuation are ignored the same way NormalizedAnswerLen
+// ignores them.
+func CheckFill(p Puzzle, fill map[string]string) CheckResult {
+	res := CheckResult{
+		Cells:   map[string]CellStatus{},
+		Entries: map[string]FillStatus{},
+	}
+
+	// Errors only describe conflicts; the filled puzzle is still usable.
+	solved, _ := FillCellsFromAnswers(p)
+	g := solved.Grid
+
+	var all []CellStatus
+	for r, row := range g.Cells {
+		for c, cell := range row {
+			if cell.IsBlock {
+				continue
+			}
+			st := checkCell(cell, fill[CellKey(r, c)])
+			res.Cells[CellKey(r, c)] = st
+			all = append(all, st)
+		}
+	}
+
+	for _, e := range p.Entries {
+		sts := make([]CellStatus, 0, len(e.Cells))
+		for _, cr := range e.Cells {
+			if st, ok := res.Cells[CellKey(cr.R, cr.C)]; ok {
+				sts = append(sts, st)
+			}
+		}
+		res.Entries[e.ID] = summarise(sts)
+	}
+
+	res.Grid = summarise(all)
+	return res
+}
+
+// CheckSession is CheckFill for a session's fill, reporting revealed cells
+// that still hold their solution as CellRevealed.
+func CheckSession(p Puzzle, sess SolveSession) CheckResult {
+	res := CheckFill(p, sess.GridState)
+	for key, revealed := range sess.Revealed {
+		if revealed && res.Cells[key] == CellCorrect {
+			res.Cells[key] = CellRevealed
+		}
+	}
+	return res
+}
+
+func checkCell(cell Cell, value string) CellStatus {
+	switch {
+	case cell.IsGiven:
+		return CellGiven
+	case NormalizeAnswer(value) == "":
+		return CellEmpty
+	case cell.Solution == "":
+		return CellUnknown
+	case CellValueMatches(cell.Solution, value):
+		return CellCorrect
+	default:
+		return CellIncorrect
+	}
+}
+
+func summarise(sts []CellStatus) FillStatus {
+	filled, empty := 0, 0
+	for _, st := range sts {
+		switch st {
+		case CellIncorrect:
+			return FillIncorrect
+		case CellEmpty:
+			empty++
+		case CellCorrect, CellUnknown, CellRevealed:
+			filled++
+		}
+	}
+	switch {
+	case empty == 0:
+		return FillCorrect
+	case filled == 0:
+		return FillEmpty
+	default:
+		return FillIncomplete
+	}
+}
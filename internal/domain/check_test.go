@@ -0,0 +1,57 @@
+package domain
+
+import "testing"
+
+func TestCheckFill(t *testing.T) {
+	p := solvedPuzzle()
+	p.Grid.Cells[2][1].IsGiven = true
+	p.Grid.Cells[2][1].Solution = "E"
+
+	res := CheckFill(p, map[string]string{})
+	if res.Grid != FillEmpty || res.Cells["2,1"] != CellGiven || res.Entries["3a"] != FillEmpty {
+		t.Fatalf("empty fill: %+v", res)
+	}
+
+	fill := map[string]string{
+		"0,0": "c", "0,1": "A", "0,2": "t", // 1a correct, case-insensitive
+		"1,0": "X", // 1d wrong
+	}
+	res = CheckFill(p, fill)
+	if res.Cells["0,0"] != CellCorrect || res.Cells["1,0"] != CellIncorrect || res.Cells["2,0"] != CellEmpty {
+		t.Fatalf("cells: %+v", res.Cells)
+	}
+	want := map[string]FillStatus{"1a": FillCorrect, "1d": FillIncorrect, "2d": FillIncomplete, "3a": FillEmpty}
+	for id, st := range want {
+		if res.Entries[id] != st {
+			t.Fatalf("entry %s=%q want %q", id, res.Entries[id], st)
+		}
+	}
+	if res.Grid != FillIncorrect {
+		t.Fatalf("grid=%q want incorrect", res.Grid)
+	}
+
+	fill = map[string]string{
+		"0,0": "C", "0,1": "A", "0,2": "T",
+		"1,0": "O", "1,2": "O",
+		"2,0": "W", "2,2": "T",
+	}
+	if res = CheckFill(p, fill); res.Grid != FillCorrect {
+		t.Fatalf("full fill: grid=%q cells=%+v", res.Grid, res.Cells)
+	}
+}
+
+func TestCheckSession_Revealed(t *testing.T) {
+	p := solvedPuzzle()
+	sess := SolveSession{
+		GridState: map[string]string{"0,0": "C", "0,1": "A", "0,2": "T"},
+		Revealed:  map[string]bool{"0,1": true},
+	}
+
+	res := CheckSession(p, sess)
+	if res.Cells["0,0"] != CellCorrect || res.Cells["0,1"] != CellRevealed {
+		t.Fatalf("cells: %+v", res.Cells)
+	}
+	if res.Entries["1a"] != FillCorrect {
+		t.Fatalf("entry 1a=%q want correct", res.Entries["1a"])
+	}
+}
@@ -1,8 +1,25 @@
 package domain
 
 type Clue struct {
-	EntryID    string
-	Text       string
-	Explanation *string
-	Tags       []string
+	EntryID string `json:"entryId"`
+
+	// LinkedEntryIDs lists the further entries a multi-part clue covers, in
+	// the order the answer runs through them (the 12 in "5,12 across").
+	// Each linked entry gets a "See 5" stub in the public view.
+	LinkedEntryIDs []string `json:"linkedEntryIds,omitempty"`
+
+	// Enum is the combined enumeration of a linked clue, checked against
+	// the total letter count of all its entries. Optional.
+	Enum string `json:"enum,omitempty"`
+
+	Text        string   `json:"text"`
+	Explanation *string  `json:"explanation,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
+// EntryIDs returns every entry the clue covers, primary entry first.
+func (c Clue) EntryIDs() []string {
+	ids := make([]string, 0, 1+len(c.LinkedEntryIDs))
+	ids = append(ids, c.EntryID)
+	return append(ids, c.LinkedEntryIDs...)
 }
@@ -0,0 +1,89 @@
+package domain
+
+import "testing"
+
+func linkedPuzzle() Puzzle {
+	g := makeGrid(3, 3, map[[2]int]bool{
+		{1, 1}: true,
+	})
+	entries := GenerateEntries(g)
+	return Puzzle{
+		ID: "p1", Title: "Test", Type: PuzzleCryptic, Rows: 3, Cols: 3, Grid: g,
+		Entries: entries,
+		Clues: []Clue{
+			{EntryID: "1a", LinkedEntryIDs: []string{"3a"}, Enum: "2,4", Text: "Linked clue"},
+			{EntryID: "1d", Text: "Down clue"},
+		},
+	}
+}
+
+func TestValidateClues_Linked(t *testing.T) {
+	p := linkedPuzzle()
+	if err := ValidatePuzzle(p); err != nil {
+		t.Fatalf("expected OK, got %v", err)
+	}
+
+	p.Clues[0].Enum = "3,4"
+	if err := ValidatePuzzle(p); err == nil {
+		t.Fatalf("expected combined enum error, got nil")
+	}
+
+	p = linkedPuzzle()
+	p.Clues[1].LinkedEntryIDs = []string{"3a"}
+	if err := ValidatePuzzle(p); err == nil {
+		t.Fatalf("expected doubly-claimed entry error, got nil")
+	}
+}
+
+func TestToPublic_SeeStubs(t *testing.T) {
+	p := linkedPuzzle()
+	pub := ToPublic(p)
+	if len(pub.Clues) != 3 || pub.Clues[1].Text != "See 1" || pub.Clues[1].SeeEntryID != "1a" {
+		t.Fatalf("unexpected public clues: %+v", pub.Clues)
+	}
+
+	// Stubs name the direction when it differs from the linked entry's.
+	p.Clues[1].LinkedEntryIDs = []string{"3a"}
+	p.Clues[0].LinkedEntryIDs = []string{"2d"}
+
+	pub = ToPublic(p)
+	if len(pub.Clues) != 4 {
+		t.Fatalf("got %d public clues, want 4: %+v", len(pub.Clues), pub.Clues)
+	}
+
+	want := map[string]string{"2d": "See 1 across", "3a": "See 1 down"}
+	for _, c := range pub.Clues {
+		if c.SeeEntryID == "" {
+			continue
+		}
+		if c.Text != want[c.EntryID] {
+			t.Fatalf("stub for %s: got %q want %q", c.EntryID, c.Text, want[c.EntryID])
+		}
+		delete(want, c.EntryID)
+	}
+	if len(want) != 0 {
+		t.Fatalf("missing stubs: %v", want)
+	}
+
+	// Links to unknown entries get no stub.
+	p.Clues[0].LinkedEntryIDs = []string{"9d"}
+	p.Clues[1].LinkedEntryIDs = nil
+	if pub = ToPublic(p); len(pub.Clues) != 2 {
+		t.Fatalf("stub made for unknown entry: %+v", pub.Clues)
+	}
+}
+
+func TestValidateClues_WithoutEntries(t *testing.T) {
+	// Clues on a puzzle stored without entries are checked against the
+	// entries the grid implies.
+	p := linkedPuzzle()
+	p.Entries = nil
+	if err := ValidatePuzzle(p); err != nil {
+		t.Fatalf("expected OK, got %v", err)
+	}
+
+	p.Clues[1].EntryID = "9d"
+	if err := ValidatePuzzle(p); err == nil {
+		t.Fatalf("expected error for clue on unknown entry")
+	}
+}
@@ -0,0 +1,62 @@
+package domain
+
+import "time"
+
+// SolveTime is how long the session's timer has run as of now.
+func (s SolveSession) SolveTime(now time.Time) time.Duration {
+	d := time.Duration(s.TimerElapsedMs) * time.Millisecond
+	if !s.TimerRunningSince.IsZero() && now.After(s.TimerRunningSince) {
+		d += now.Sub(s.TimerRunningSince)
+	}
+	return d
+}
+
+// PauseTimer stops the session's timer, banking the time run so far.
+// Pausing a stopped timer does nothing.
+func PauseTimer(s SolveSession, now time.Time) SolveSession {
+	if s.TimerRunningSince.IsZero() {
+		return s
+	}
+	s.TimerElapsedMs = s.SolveTime(now).Milliseconds()
+	s.TimerRunningSince = time.Time{}
+	return s
+}
+
+// ResumeTimer starts the session's timer again. Resuming a running timer or
+// a completed session does nothing.
+func ResumeTimer(s SolveSession, now time.Time) SolveSession {
+	if !s.TimerRunningSince.IsZero() || !s.CompletedAt.IsZero() {
+		return s
+	}
+	s.TimerRunningSince = now
+	return s
+}
+
+// IsComplete reports whether a session's fill matches every solution of the
+// puzzle. Cells the puzzle has no solution for can't be judged, so a puzzle
+// missing any solution never completes.
+func IsComplete(p Puzzle, s SolveSession) bool {
+	res := CheckSession(p, s)
+	if res.Grid != FillCorrect {
+		return false
+	}
+	for _, st := range res.Cells {
+		if st == CellUnknown {
+			return false
+		}
+	}
+	return true
+}
+
+// MarkCompleted records the session as completed if its fill is now
+// complete: it sets CompletedAt and SolvedClean and stops the timer.
+// Completion is recorded once; later edits don't undo it.
+func MarkCompleted(p Puzzle, s SolveSession, now time.Time) SolveSession {
+	if !s.CompletedAt.IsZero() || !IsComplete(p, s) {
+		return s
+	}
+	s = PauseTimer(s, now)
+	s.CompletedAt = now
+	s.SolvedClean = s.ChecksUsed == 0 && s.RevealsUsed == 0
+	return s
+}
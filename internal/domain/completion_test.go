@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func solvedFill() map[string]string {
+	return map[string]string{
+		"0,0": "C", "0,1": "A", "0,2": "T",
+		"1,0": "O", "1,2": "O",
+		"2,0": "W", "2,1": "E", "2,2": "T",
+	}
+}
+
+func TestMarkCompleted(t *testing.T) {
+	p := solvedPuzzle()
+	start := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	now := start.Add(90 * time.Second)
+
+	fill := solvedFill()
+	fill["2,2"] = "" // one cell short
+	sess := SolveSession{GridState: fill, TimerRunningSince: start}
+	if got := MarkCompleted(p, sess, now); !got.CompletedAt.IsZero() {
+		t.Fatalf("incomplete fill marked completed: %+v", got)
+	}
+
+	sess.GridState = solvedFill()
+	got := MarkCompleted(p, sess, now)
+	if !got.CompletedAt.Equal(now) || !got.SolvedClean {
+		t.Fatalf("clean solve not recorded: %+v", got)
+	}
+	if !got.TimerRunningSince.IsZero() || got.SolveTime(now.Add(time.Hour)) != 90*time.Second {
+		t.Fatalf("timer not stopped at completion: %+v", got)
+	}
+
+	// Completion sticks.
+	got.GridState = map[string]string{}
+	if again := MarkCompleted(p, got, now.Add(time.Minute)); !again.CompletedAt.Equal(now) {
+		t.Fatalf("completion changed: %+v", again)
+	}
+
+	sess.RevealsUsed = 1
+	if got := MarkCompleted(p, sess, now); got.CompletedAt.IsZero() || got.SolvedClean {
+		t.Fatalf("solve with reveals marked clean: %+v", got)
+	}
+
+	// Without solutions there's nothing to complete against.
+	p.Entries[0].Answer = ""
+	p.Entries[1].Answer = ""
+	sess = SolveSession{GridState: solvedFill()}
+	if got := MarkCompleted(p, sess, now); !got.CompletedAt.IsZero() {
+		t.Fatalf("puzzle without solutions completed: %+v", got)
+	}
+}
+
+func TestSolveTimer(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	sess := SolveSession{TimerRunningSince: t0}
+
+	sess = PauseTimer(sess, t0.Add(10*time.Second))
+	if sess.TimerElapsedMs != 10000 || !sess.TimerRunningSince.IsZero() {
+		t.Fatalf("after pause: %+v", sess)
+	}
+	// Time while paused doesn't count; pausing again is a no-op.
+	sess = PauseTimer(sess, t0.Add(time.Hour))
+	if got := sess.SolveTime(t0.Add(time.Hour)); got != 10*time.Second {
+		t.Fatalf("paused SolveTime=%v", got)
+	}
+
+	sess = ResumeTimer(sess, t0.Add(time.Hour))
+	sess = ResumeTimer(sess, t0.Add(2*time.Hour)) // already running
+	if got := sess.SolveTime(t0.Add(time.Hour + 5*time.Second)); got != 15*time.Second {
+		t.Fatalf("resumed SolveTime=%v", got)
+	}
+
+	sess = PauseTimer(sess, t0.Add(time.Hour+5*time.Second))
+	sess.CompletedAt = t0.Add(time.Hour + 5*time.Second)
+	if got := ResumeTimer(sess, t0.Add(3*time.Hour)); !got.TimerRunningSince.IsZero() {
+		t.Fatalf("completed session resumed: %+v", got)
+	}
+}
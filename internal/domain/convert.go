@@ -1,6 +1,11 @@
 package domain
 
+import "fmt"
+
+// ToPublic strips answers and solutions from a puzzle for solvers. The only
+// solutions it keeps are those of given cells, which solvers see pre-filled.
 func ToPublic(p Puzzle) PuzzlePublic {
+	p.NormalizeGrid()
 	pub := PuzzlePublic{
 		ID:      p.ID,
 		Title:   p.Title,
@@ -10,8 +15,18 @@ func ToPublic(p Puzzle) PuzzlePublic {
 		Grid:    GridPublic{Rows: p.Grid.Rows, Cols: p.Grid.Cols},
 		Entries: make([]EntryPublic, 0, len(p.Entries)),
 		Clues:   make([]CluePublic, 0, len(p.Clues)),
+
+		Revision: p.Revision,
+
+		Author:     p.Author,
+		Copyright:  p.Copyright,
+		Difficulty: p.Difficulty,
+		Preamble:   p.Preamble,
+		Notes:      p.Notes,
 	}
 
+	pub.PublishedOn = p.PublishedOn.String()
+
 	// Grid cells
 	if p.Grid.Rows > 0 && p.Grid.Cols > 0 && len(p.Grid.Cells) == p.Grid.Rows {
 		pub.Grid.Cells = make([][]CellPublic, p.Grid.Rows)
@@ -20,10 +35,22 @@ func ToPublic(p Puzzle) PuzzlePublic {
 			for c := 0; c < p.Grid.Cols; c++ {
 				cell := p.Grid.Cells[r][c]
 				pub.Grid.Cells[r][c] = CellPublic{
-					R:     r,
-					C:     c,
-					Block: cell.IsBlock,
-					Given: cell.IsGiven,
+					R:         r,
+					C:         c,
+					Block:     cell.IsBlock,
+					Given:     cell.IsGiven,
+					BarRight:  cell.BarRight,
+					BarBottom: cell.BarBottom,
+				}
+				if cell.IsGiven {
+					pub.Grid.Cells[r][c].Letter = NormalizeAnswer(cell.Solution)
+				}
+				if a := cell.Annotation; a != nil {
+					pub.Grid.Cells[r][c].Annotation = &AnnotationPublic{
+						Shape: a.Shape,
+						Fill:  a.Fill,
+						Label: a.Label,
+					}
 				}
 			}
 		}
@@ -32,23 +59,95 @@ func ToPublic(p Puzzle) PuzzlePublic {
 	// Entries (no answers)
 	for _, e := range p.Entries {
 		pub.Entries = append(pub.Entries, EntryPublic{
-			ID:    e.ID,
-			Dir:   e.Dir,
-			Num:   e.Num,
-			Cells: e.Cells,
-			Enum:  e.Enum,
+			ID:        e.ID,
+			Dir:       e.Dir,
+			Num:       e.Num,
+			Cells:     e.Cells,
+			Enum:      e.Enum,
+			EnumParts: enumParts(e.Enum),
 		})
 	}
 
-	// Clues (explanations optional)
+	// Clues (explanations optional). Linked clues also get a "See N" stub
+	// for each of their further entries.
+	byID := map[string]Entry{}
+	for _, e := range p.Entries {
+		if e.ID != "" {
+			byID[e.ID] = e
+		}
+	}
 	for _, c := range p.Clues {
-		pub.Clues = append(pub.Clues, CluePublic{
+		cp := CluePublic{
 			EntryID:     c.EntryID,
+			Enum:        c.Enum,
+			EnumParts:   enumParts(c.Enum),
 			Text:        c.Text,
 			Explanation: c.Explanation,
 			Tags:        c.Tags,
-		})
+		}
+		if len(c.LinkedEntryIDs) > 0 {
+			cp.EntryIDs = c.EntryIDs()
+		}
+		pub.Clues = append(pub.Clues, cp)
+
+		// A stub needs both ends to say where to look; links to unknown
+		// entries (which ValidatePuzzle rejects) get none.
+		primary, ok := byID[c.EntryID]
+		if !ok {
+			continue
+		}
+		for _, id := range c.LinkedEntryIDs {
+			linked, ok := byID[id]
+			if !ok {
+				continue
+			}
+			pub.Clues = append(pub.Clues, CluePublic{
+				EntryID:    id,
+				SeeEntryID: c.EntryID,
+				Text:       seeText(primary, linked),
+			})
+		}
 	}
 
 	return pub
 }
+
+func ToSummary(p Puzzle) PuzzleSummary {
+	sum := PuzzleSummary{
+		ID:         p.ID,
+		Revision:   p.Revision,
+		Title:      p.Title,
+		Type:       p.Type,
+		Rows:       p.Rows,
+		Cols:       p.Cols,
+		Author:     p.Author,
+		Difficulty: p.Difficulty,
+	}
+	sum.PublishedOn = p.PublishedOn.String()
+	return sum
+}
+
+// seeText builds the stub text for a linked entry, e.g. "See 5" or, when the
+// primary entry runs the other way, "See 5 down".
+func seeText(primary, linked Entry) string {
+	if primary.Num == 0 {
+		return "See " + primary.ID
+	}
+	if primary.Dir != linked.Dir {
+		return fmt.Sprintf("See %d %s", primary.Num, primary.Dir)
+	}
+	return fmt.Sprintf("See %d", primary.Num)
+}
+
+// enumParts returns the structured parts of an enumeration, or nil if it is
+// empty or doesn't parse.
+func enumParts(raw string) []EnumPart {
+	if raw == "" {
+		return nil
+	}
+	en, err := ParseEnum(raw)
+	if err != nil {
+		return nil
+	}
+	return en.PublicParts()
+}
@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// Date is a calendar date. It is encoded as YYYY-MM-DD (DateLayout) both
+// ways, the form the public view and the list filters use, so a client can
+// send back the date it read. The zero Date is unset and encodes as "".
+type Date struct {
+	t time.Time // UTC midnight
+}
+
+// NewDate returns the date y-m-d, normalised like time.Date.
+func NewDate(y int, m time.Month, d int) Date {
+	return Date{t: time.Date(y, m, d, 0, 0, 0, 0, time.UTC)}
+}
+
+// ParseDate parses a YYYY-MM-DD date.
+func ParseDate(s string) (Date, error) {
+	t, err := time.Parse(DateLayout, s)
+	if err != nil {
+		return Date{}, fmt.Errorf("invalid date %q (want YYYY-MM-DD)", s)
+	}
+	return Date{t: t}, nil
+}
+
+func (d Date) IsZero() bool { return d.t.IsZero() }
+
+func (d Date) Before(o Date) bool { return d.t.Before(o.t) }
+
+func (d Date) After(o Date) bool { return d.t.After(o.t) }
+
+// String returns the date as YYYY-MM-DD, or "" if it is unset.
+func (d Date) String() string {
+	if d.IsZero() {
+		return ""
+	}
+	return d.t.Format(DateLayout)
+}
+
+func (d Date) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText accepts YYYY-MM-DD, or "" for an unset date.
+func (d *Date) UnmarshalText(b []byte) error {
+	if len(b) == 0 {
+		*d = Date{}
+		return nil
+	}
+	parsed, err := ParseDate(string(b))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestDate_JSON(t *testing.T) {
+	var p Puzzle
+	if err := json.Unmarshal([]byte(`{"publishedOn": "2024-05-01"}`), &p); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if p.PublishedOn != NewDate(2024, 5, 1) {
+		t.Fatalf("PublishedOn=%v", p.PublishedOn)
+	}
+
+	// What the API sends out, a client can send back.
+	out, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), `"publishedOn":"2024-05-01"`) {
+		t.Fatalf("marshal=%s", out)
+	}
+	if pub, _ := json.Marshal(ToPublic(p)); !strings.Contains(string(pub), `"publishedOn":"2024-05-01"`) {
+		t.Fatalf("public=%s", pub)
+	}
+
+	// Unset dates are left out.
+	out, _ = json.Marshal(Puzzle{})
+	if strings.Contains(string(out), "publishedOn") {
+		t.Fatalf("zero date marshalled: %s", out)
+	}
+
+	for _, in := range []string{`"2024-05-01T00:00:00Z"`, `"01/05/2024"`, `20240501`} {
+		var d Date
+		if err := json.Unmarshal([]byte(in), &d); err == nil {
+			t.Fatalf("%s: expected error, got %v", in, d)
+		}
+	}
+}
@@ -1,5 +1,10 @@
 package domain
 
+import (
+	"strconv"
+	"strings"
+)
+
 type Direction string
 
 const (
@@ -8,15 +13,44 @@ const (
 )
 
 type Entry struct {
-	ID    string
-	Dir   Direction
-	Num   int
-	Cells []CellRef
-	Enum  string
-	Answer string
+	ID     string    `json:"id"`
+	Dir    Direction `json:"dir"`
+	Num    int       `json:"num"`
+	Cells  []CellRef `json:"cells"`
+	Enum   string    `json:"enum,omitempty"`
+	Answer string    `json:"answer,omitempty"`
 }
 
 type CellRef struct {
-	R int
-	C int
+	R int `json:"r"`
+	C int `json:"c"`
+}
+
+// EntryID returns the canonical ID for an entry: its number followed by
+// "a" or "d", e.g. "1a" or "14d".
+func EntryID(num int, dir Direction) string {
+	suffix := "a"
+	if dir == Down {
+		suffix = "d"
+	}
+	return strconv.Itoa(num) + suffix
+}
+
+// idDirection returns the direction encoded in a canonical entry ID, or ""
+// if the ID isn't in canonical form (custom IDs are allowed).
+func idDirection(id string) Direction {
+	var dir Direction
+	var digits string
+	switch {
+	case strings.HasSuffix(id, "a"):
+		dir, digits = Across, strings.TrimSuffix(id, "a")
+	case strings.HasSuffix(id, "d"):
+		dir, digits = Down, strings.TrimSuffix(id, "d")
+	default:
+		return ""
+	}
+	if n, err := strconv.Atoi(digits); err != nil || n <= 0 {
+		return ""
+	}
+	return dir
 }
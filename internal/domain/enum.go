@@ -7,41 +7,54 @@ import (
 	"unicode"
 )
 
+// EnumSep is the separator between two parts of an enumeration.
+type EnumSep string
+
+const (
+	SepWord       EnumSep = "," // word break: "3,5"
+	SepHyphen     EnumSep = "-" // hyphen: "4-4"
+	SepApostrophe EnumSep = "'" // apostrophe: "4'1"
+)
+
 // Enum represents an enumeration like:
 // "3"        -> Parts=[3], Total=3
-// "3,5"      -> Parts=[3,5], Total=8
-// "4-4"      -> Parts=[4,4], Total=8
-// "3,4-5,2"  -> Parts=[3,4,5,2], Total=14
+// "3,5"      -> Parts=[3,5], Seps=[,], Total=8
+// "4-4"      -> Parts=[4,4], Seps=[-], Total=8
+// "4'1"      -> Parts=[4,1], Seps=['], Total=5
+// "3,4-5,2"  -> Parts=[3,4,5,2], Seps=[, - ,], Total=14
+//
+// Seps[i] is the separator between Parts[i] and Parts[i+1].
 type Enum struct {
 	Raw   string
 	Parts []int
+	Seps  []EnumSep
 	Total int
 }
 
 // ParseEnum parses common crossword enumerations:
-// - digits separated by ',' and/or '-' (commas and hyphens both mean "split into parts")
+// - digits separated by "," (word break), "-" (hyphen) or "'" (apostrophe)
 // - ignores whitespace
-//
-// It does NOT attempt to encode word boundaries semantics (comma vs hyphen).
-// For validation we only need the total length and parts.
 func ParseEnum(s string) (Enum, error) {
 	raw := strings.TrimSpace(s)
 	if raw == "" {
 		return Enum{}, fmt.Errorf("enum is empty")
 	}
 
-	// normalize: remove spaces
+	// normalize: remove spaces, straighten curly apostrophes
 	compact := strings.Map(func(r rune) rune {
 		if unicode.IsSpace(r) {
 			return -1
 		}
+		if r == '\u2019' {
+			return '\''
+		}
 		return r
 	}, raw)
 
 	// Strict delimiter validation:
 	// - cannot start/end with delimiter
 	// - cannot contain consecutive delimiters
-	isDelim := func(r byte) bool { return r == ',' || r == '-' }
+	isDelim := func(r byte) bool { return r == ',' || r == '-' || r == '\'' }
 
 	if len(compact) == 0 {
 		return Enum{}, fmt.Errorf("enum is empty")
@@ -55,37 +68,144 @@ func ParseEnum(s string) (Enum, error) {
 		}
 	}
 
-	// split on comma or hyphen
-	fields := strings.FieldsFunc(compact, func(r rune) bool {
-		return r == ',' || r == '-'
-	})
+	var (
+		parts []int
+		seps  []EnumSep
+		total int
+	)
+	addPart := func(f string) error {
+		n, err := strconv.Atoi(f)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("enum %q has invalid part %q", raw, f)
+		}
+		parts = append(parts, n)
+		total += n
+		return nil
+	}
 
-	if len(fields) == 0 {
-		return Enum{}, fmt.Errorf("enum %q has no parts", raw)
+	start := 0
+	for i := 0; i < len(compact); i++ {
+		if !isDelim(compact[i]) {
+			continue
+		}
+		if err := addPart(compact[start:i]); err != nil {
+			return Enum{}, err
+		}
+		seps = append(seps, EnumSep(compact[i:i+1]))
+		start = i + 1
+	}
+	if err := addPart(compact[start:]); err != nil {
+		return Enum{}, err
 	}
 
-	parts := make([]int, 0, len(fields))
-	total := 0
+	return Enum{Raw: raw, Parts: parts, Seps: seps, Total: total}, nil
+}
 
-	for _, f := range fields {
-		if f == "" {
-			return Enum{}, fmt.Errorf("enum %q contains empty part", raw)
+// PublicParts returns the enumeration as a list of parts, each carrying the
+// separator that follows it.
+func (en Enum) PublicParts() []EnumPart {
+	out := make([]EnumPart, len(en.Parts))
+	for i, n := range en.Parts {
+		out[i].Len = n
+		if i < len(en.Seps) {
+			out[i].Sep = en.Seps[i]
 		}
-		n, err := strconv.Atoi(f)
-		if err != nil || n <= 0 {
-			return Enum{}, fmt.Errorf("enum %q has invalid part %q", raw, f)
+	}
+	return out
+}
+
+// Punctuate inserts the enumeration's separators into a run of letters,
+// writing word breaks as spaces and hyphens and apostrophes as themselves.
+// Letters that don't add up to the enumeration's total are returned as is.
+func (en Enum) Punctuate(letters string) string {
+	rs := []rune(letters)
+	if len(rs) != en.Total {
+		return letters
+	}
+	var b strings.Builder
+	i := 0
+	for j, n := range en.Parts {
+		b.WriteString(string(rs[i : i+n]))
+		i += n
+		if j < len(en.Seps) {
+			if en.Seps[j] == SepWord {
+				b.WriteByte(' ')
+			} else {
+				b.WriteString(string(en.Seps[j]))
+			}
 		}
-		parts = append(parts, n)
-		total += n
 	}
+	return b.String()
+}
+
+// MatchAnswer checks that an answer's punctuation agrees with the
+// enumeration: spaces or commas for word breaks, hyphens and apostrophes
+// where the enumeration has them, and matching part lengths.
+//
+// An answer written without any punctuation ("ICECREAM" for 3-5) is
+// accepted; only its total length is checked, by the caller.
+func (en Enum) MatchAnswer(answer string) error {
+	parts, seps := splitAnswer(answer)
+	if len(parts) <= 1 {
+		return nil
+	}
+	if len(parts) != len(en.Parts) {
+		return fmt.Errorf("answer %q has %d parts, enum %q has %d", answer, len(parts), en.Raw, len(en.Parts))
+	}
+	for i, p := range parts {
+		if n := NormalizedAnswerLen(p); n != en.Parts[i] {
+			return fmt.Errorf("answer %q part %d has %d letters, enum %q wants %d", answer, i+1, n, en.Raw, en.Parts[i])
+		}
+	}
+	for i, sep := range seps {
+		if sep != en.Seps[i] {
+			return fmt.Errorf("answer %q has %q after part %d, enum %q has %q", answer, sep, i+1, en.Raw, en.Seps[i])
+		}
+	}
+	return nil
+}
 
-	return Enum{Raw: raw, Parts: parts, Total: total}, nil
+// splitAnswer splits an answer at its punctuation, classifying each break
+// as a word break, hyphen or apostrophe. Leading and trailing punctuation
+// is ignored.
+func splitAnswer(answer string) (parts []string, seps []EnumSep) {
+	var cur strings.Builder
+	var pending EnumSep
+	for _, r := range answer {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if pending != "" && cur.Len() > 0 {
+				parts = append(parts, cur.String())
+				seps = append(seps, pending)
+				cur.Reset()
+			}
+			pending = ""
+			cur.WriteRune(r)
+			continue
+		}
+		// A hyphen or apostrophe wins over surrounding spaces.
+		switch {
+		case r == '-':
+			pending = SepHyphen
+		case r == '\'' || r == '\u2019':
+			if pending != SepHyphen {
+				pending = SepApostrophe
+			}
+		case pending == "":
+			pending = SepWord
+		}
+	}
+	if cur.Len() > 0 {
+		parts = append(parts, cur.String())
+	}
+	return parts, seps
 }
 
 // NormalizedAnswerLen returns the letter-count of an answer ignoring:
 // spaces, hyphens, apostrophes, and underscores.
 //
 // This matches typical crossword expectations where enumeration counts letters.
+// A rebus cell counts once per letter, so an entry's letter count can exceed
+// its cell count; use Grid.LetterCount for the number an entry expects.
 func NormalizedAnswerLen(answer string) int {
 	n := 0
 	for _, r := range answer {
@@ -101,3 +221,15 @@ func NormalizedAnswerLen(answer string) int {
 	}
 	return n
 }
+
+// NormalizeAnswer upper-cases an answer or cell value and drops everything
+// NormalizedAnswerLen doesn't count, so "o'neil" and "ONEIL" compare equal.
+func NormalizeAnswer(answer string) string {
+	var b strings.Builder
+	for _, r := range answer {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(unicode.ToUpper(r))
+		}
+	}
+	return b.String()
+}
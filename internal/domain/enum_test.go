@@ -13,6 +13,9 @@ func TestParseEnum(t *testing.T) {
 		{"4-4", 8, true},
 		{"3,4-5,2", 14, true},
 		{" 3 , 5 ", 8, true},
+		{"4'1", 5, true},
+		{"2,3-4", 9, true},
+		{"4'", 0, false},
 		{"", 0, false},
 		{"0", 0, false},
 		{"3,,5", 0, false},
@@ -33,6 +36,54 @@ func TestParseEnum(t *testing.T) {
 	}
 }
 
+func TestParseEnum_Separators(t *testing.T) {
+	e, err := ParseEnum("2,3-4'1")
+	if err != nil {
+		t.Fatalf("ParseEnum: %v", err)
+	}
+	want := []EnumPart{{2, SepWord}, {3, SepHyphen}, {4, SepApostrophe}, {1, ""}}
+	got := e.PublicParts()
+	if len(got) != len(want) {
+		t.Fatalf("parts=%+v want=%+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("parts=%+v want=%+v", got, want)
+		}
+	}
+}
+
+func TestEnumMatchAnswer(t *testing.T) {
+	tests := []struct {
+		enum, answer string
+		wantOk       bool
+	}{
+		{"3,5", "NEW YORK", false},
+		{"3,4", "NEW YORK", true},
+		{"3-5", "ICE-CREAM", true},
+		{"3-5", "ICE CREAM", false},
+		{"3-5", "ICECREAM", true},
+		{"4'1", "WHAT'S", true},
+		{"4'1", "WHAT S", false},
+		{"2,3-4", "IN-TIP TOES", false},
+		{"2,3-4", "IN TIP-TOES", true},
+	}
+
+	for _, tt := range tests {
+		en, err := ParseEnum(tt.enum)
+		if err != nil {
+			t.Fatalf("ParseEnum(%q): %v", tt.enum, err)
+		}
+		err = en.MatchAnswer(tt.answer)
+		if tt.wantOk && err != nil {
+			t.Fatalf("MatchAnswer(%q, %q) unexpected error: %v", tt.enum, tt.answer, err)
+		}
+		if !tt.wantOk && err == nil {
+			t.Fatalf("MatchAnswer(%q, %q) expected error, got none", tt.enum, tt.answer)
+		}
+	}
+}
+
 func TestNormalizedAnswerLen(t *testing.T) {
 	tests := []struct {
 		in   string
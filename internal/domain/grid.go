@@ -1,15 +1,88 @@
 package domain
 
 type Cell struct {
-	R       int
-	C       int
-	IsBlock bool
-	Solution *rune
-	IsGiven bool
+	R       int  `json:"r"`
+	C       int  `json:"c"`
+	IsBlock bool `json:"block,omitempty"`
+
+	// Solution is the cell's answer, empty when unknown. It is usually one
+	// letter but rebus cells hold several ("TH", "HEART"); see NormalizeAnswer
+	// for how it is compared.
+	Solution string `json:"solution,omitempty"`
+	IsGiven  bool   `json:"given,omitempty"`
+
+	// Bars (barred grids): a bar on the right edge ends an across entry
+	// after this cell; a bar on the bottom edge ends a down entry.
+	BarRight  bool `json:"barRight,omitempty"`
+	BarBottom bool `json:"barBottom,omitempty"`
+
+	// Annotation is an optional circle, shading or label for themed puzzles.
+	Annotation *CellAnnotation `json:"annotation,omitempty"`
 }
 
 type Grid struct {
-	Rows  int
-	Cols  int
-	Cells [][]Cell
+	Rows  int      `json:"rows"`
+	Cols  int      `json:"cols"`
+	Cells [][]Cell `json:"cells"`
+}
+
+// IsWhite reports whether (r,c) is inside the grid and not a block.
+func (g Grid) IsWhite(r, c int) bool {
+	if r < 0 || c < 0 || r >= g.Rows || c >= g.Cols || r >= len(g.Cells) || c >= len(g.Cells[r]) {
+		return false
+	}
+	return !g.Cells[r][c].IsBlock
+}
+
+// barAfter reports whether the cell at (r,c) has a bar on its trailing
+// edge in the given direction (right for across, bottom for down).
+func (g Grid) barAfter(r, c int, dir Direction) bool {
+	if r < 0 || c < 0 || r >= len(g.Cells) || c >= len(g.Cells[r]) {
+		return false
+	}
+	cell := g.Cells[r][c]
+	if dir == Across {
+		return cell.BarRight
+	}
+	return cell.BarBottom
+}
+
+// cellWidth is the number of answer letters the cell at (r,c) holds: the
+// letter count of its solution for rebus cells, otherwise 1.
+func (g Grid) cellWidth(r, c int) int {
+	if r < 0 || c < 0 || r >= len(g.Cells) || c >= len(g.Cells[r]) {
+		return 1
+	}
+	if n := NormalizedAnswerLen(g.Cells[r][c].Solution); n > 1 {
+		return n
+	}
+	return 1
+}
+
+// LetterCount returns how many answer letters fit in the given cells,
+// counting rebus cells by the length of their solution.
+func (g Grid) LetterCount(cells []CellRef) int {
+	n := 0
+	for _, cr := range cells {
+		n += g.cellWidth(cr.R, cr.C)
+	}
+	return n
+}
+
+// SplitAnswer divides an answer between the given cells, giving each rebus
+// cell as many letters as its solution holds. The parts are normalised (see
+// NormalizeAnswer). ok is false if the answer's letter count doesn't match.
+func (g Grid) SplitAnswer(cells []CellRef, answer string) (parts []string, ok bool) {
+	letters := []rune(NormalizeAnswer(answer))
+	if len(letters) != g.LetterCount(cells) {
+		return nil, false
+	}
+	parts = make([]string, len(cells))
+	i := 0
+	for j, cr := range cells {
+		w := g.cellWidth(cr.R, cr.C)
+		parts[j] = string(letters[i : i+w])
+		i += w
+	}
+	return parts, true
 }
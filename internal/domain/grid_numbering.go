@@ -1,5 +1,9 @@
 package domain
 
+// GenerateEntries walks the grid in reading order and returns the across and
+// down entries it implies. Entries end at blocks, grid edges and bars. Each
+// entry gets its canonical ID (see EntryID); use ReconcileEntries after a
+// grid edit to keep existing IDs where entries survive.
 func GenerateEntries(grid Grid) []Entry {
 	var entries []Entry
 	num := 1
@@ -11,23 +15,33 @@ func GenerateEntries(grid Grid) []Entry {
 		return grid.Cells[r][c].IsBlock
 	}
 
+	// Entries can't continue across a bar, so a bar behaves like a block
+	// between the two cells it separates.
+	openAcross := func(r, c int) bool {
+		return !isBlock(r, c) && !isBlock(r, c+1) && !grid.barAfter(r, c, Across)
+	}
+	openDown := func(r, c int) bool {
+		return !isBlock(r, c) && !isBlock(r+1, c) && !grid.barAfter(r, c, Down)
+	}
+
 	for r := 0; r < grid.Rows; r++ {
 		for c := 0; c < grid.Cols; c++ {
 			if grid.Cells[r][c].IsBlock {
 				continue
 			}
 
-			startAcross := isBlock(r, c-1) && !isBlock(r, c+1)
-			startDown   := isBlock(r-1, c) && !isBlock(r+1, c)
+			startAcross := !openAcross(r, c-1) && openAcross(r, c)
+			startDown := !openDown(r-1, c) && openDown(r, c)
 
 			if startAcross {
-				var cells []CellRef
+				cells := []CellRef{{R: r, C: c}}
 				cc := c
-				for cc < grid.Cols && !isBlock(r, cc) {
-					cells = append(cells, CellRef{R: r, C: cc})
+				for openAcross(r, cc) {
 					cc++
+					cells = append(cells, CellRef{R: r, C: cc})
 				}
 				entries = append(entries, Entry{
+					ID:    EntryID(num, Across),
 					Dir:   Across,
 					Num:   num,
 					Cells: cells,
@@ -35,13 +49,14 @@ func GenerateEntries(grid Grid) []Entry {
 			}
 
 			if startDown {
-				var cells []CellRef
+				cells := []CellRef{{R: r, C: c}}
 				rr := r
-				for rr < grid.Rows && !isBlock(rr, c) {
-					cells = append(cells, CellRef{R: rr, C: c})
+				for openDown(rr, c) {
 					rr++
+					cells = append(cells, CellRef{R: rr, C: c})
 				}
 				entries = append(entries, Entry{
+					ID:    EntryID(num, Down),
 					Dir:   Down,
 					Num:   num,
 					Cells: cells,
@@ -0,0 +1,116 @@
+package domain
+
+import "testing"
+
+func TestGenerateEntries_Blocked(t *testing.T) {
+	g := makeGrid(3, 3, map[[2]int]bool{
+		{1, 1}: true,
+	})
+
+	entries := GenerateEntries(g)
+	// 1a, 1d, 2d, 3a
+	if len(entries) != 4 {
+		t.Fatalf("got %d entries, want 4: %+v", len(entries), entries)
+	}
+	for _, e := range entries {
+		if len(e.Cells) != 3 {
+			t.Fatalf("entry %d %s has %d cells, want 3", e.Num, e.Dir, len(e.Cells))
+		}
+	}
+}
+
+func TestGenerateEntries_Barred(t *testing.T) {
+	g := makeGrid(3, 4, nil)
+	// Split row 0 into 2+2 and column 0 into 1+2.
+	g.Cells[0][1].BarRight = true
+	g.Cells[0][0].BarBottom = true
+
+	entries := GenerateEntries(g)
+
+	type key struct {
+		num int
+		dir Direction
+	}
+	got := map[key]int{}
+	for _, e := range entries {
+		got[key{e.Num, e.Dir}] = len(e.Cells)
+	}
+
+	want := map[key]int{
+		{1, Across}: 2,
+		{2, Down}:   3,
+		{3, Across}: 2,
+		{3, Down}:   3,
+		{4, Down}:   3,
+		{5, Across}: 4,
+		{5, Down}:   2,
+		{6, Across}: 4,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d entries, want %d: %+v", len(got), len(want), entries)
+	}
+	for k, n := range want {
+		if got[k] != n {
+			t.Fatalf("%d %s: got %d cells, want %d", k.num, k.dir, got[k], n)
+		}
+	}
+}
+
+func TestValidateEntries_CrossesBar(t *testing.T) {
+	g := makeGrid(3, 3, nil)
+	g.Cells[0][0].BarRight = true
+
+	entries := []Entry{
+		{ID: "e1", Dir: Across, Num: 1, Cells: []CellRef{{0, 0}, {0, 1}, {0, 2}}},
+	}
+	p := Puzzle{ID: "p1", Title: "Test", Type: PuzzleCryptic, Rows: 3, Cols: 3, Grid: g, Entries: entries}
+
+	if err := ValidatePuzzle(p); err == nil {
+		t.Fatalf("expected bar crossing error, got nil")
+	}
+}
+
+func TestGenerateEntries_IDs(t *testing.T) {
+	g := makeGrid(3, 3, map[[2]int]bool{
+		{1, 1}: true,
+	})
+
+	var ids []string
+	for _, e := range GenerateEntries(g) {
+		ids = append(ids, e.ID)
+	}
+	want := []string{"1a", "1d", "2d", "3a"}
+	if len(ids) != len(want) {
+		t.Fatalf("ids=%v want=%v", ids, want)
+	}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Fatalf("ids=%v want=%v", ids, want)
+		}
+	}
+}
+
+func TestValidateEntries_IDs(t *testing.T) {
+	g := makeGrid(3, 3, map[[2]int]bool{
+		{1, 1}: true,
+	})
+	p := Puzzle{ID: "p1", Title: "Test", Type: PuzzleQuick, Rows: 3, Cols: 3, Grid: g}
+
+	for name, mutate := range map[string]func([]Entry){
+		"missing":   func(es []Entry) { es[0].ID = "" },
+		"duplicate": func(es []Entry) { es[1].ID = es[0].ID },
+		"direction": func(es []Entry) { es[0].ID = "1d"; es[1].ID = "1a" },
+	} {
+		p.Entries = GenerateEntries(g)
+		mutate(p.Entries)
+		if err := ValidatePuzzle(p); err == nil {
+			t.Fatalf("%s: expected id error, got nil", name)
+		}
+	}
+
+	p.Entries = GenerateEntries(g)
+	p.Clues = []Clue{{EntryID: "9a", Text: "Nowhere"}}
+	if err := ValidatePuzzle(p); err == nil {
+		t.Fatalf("expected unknown entryId error, got nil")
+	}
+}
@@ -0,0 +1,299 @@
+package domain
+
+import "fmt"
+
+// LintSeverity ranks a lint finding. Lint findings never make a puzzle
+// invalid; they flag editorial issues for a human to judge.
+type LintSeverity string
+
+const (
+	LintInfo    LintSeverity = "info"
+	LintWarning LintSeverity = "warning"
+	LintError   LintSeverity = "error"
+)
+
+// Lint finding codes. These are stable so tools can filter on them.
+const (
+	LintAsymmetric      = "asymmetric"
+	LintShortEntry      = "short-entry"
+	LintUnchecked       = "unchecked"
+	LintConsecutiveUnch = "consecutive-unches"
+	LintUnusedCell      = "unused-cell"
+	LintDisconnected    = "disconnected"
+	LintBlockDensity    = "block-density"
+)
+
+type LintFinding struct {
+	Severity LintSeverity `json:"severity"`
+	Code     string       `json:"code"`
+	Message  string       `json:"message"`
+	Cells    []CellRef    `json:"cells,omitempty"`
+}
+
+type LintReport struct {
+	Profile  string        `json:"profile"`
+	Findings []LintFinding `json:"findings"`
+}
+
+func (r *LintReport) add(sev LintSeverity, code string, cells []CellRef, format string, args ...any) {
+	r.Findings = append(r.Findings, LintFinding{
+		Severity: sev,
+		Code:     code,
+		Message:  fmt.Sprintf(format, args...),
+		Cells:    cells,
+	})
+}
+
+// LintProfile holds the thresholds a grid is judged against.
+type LintProfile struct {
+	Name string
+
+	// RequireSymmetry flags grids whose block and bar pattern has neither 180°
+	// rotational nor left-right mirror symmetry.
+	RequireSymmetry bool
+
+	// MinEntryLen is the shortest acceptable entry.
+	MinEntryLen int
+
+	// MaxUncheckedRatio is the largest share of an entry's cells that may
+	// be unchecked (belong to no crossing entry). 0 means fully checked.
+	MaxUncheckedRatio float64
+
+	// MaxConsecutiveUnches is the longest run of unchecked cells allowed
+	// within one entry.
+	MaxConsecutiveUnches int
+
+	// MaxBlockDensity is the largest share of cells that may be blocks.
+	MaxBlockDensity float64
+}
+
+var (
+	// LintProfileAmerican: every cell checked, low block count.
+	LintProfileAmerican = LintProfile{
+		Name:                 "american",
+		RequireSymmetry:      true,
+		MinEntryLen:          3,
+		MaxUncheckedRatio:    0,
+		MaxConsecutiveUnches: 0,
+		MaxBlockDensity:      0.20,
+	}
+
+	// LintProfileUKCryptic: roughly half of each entry checked, never two
+	// unches in a row.
+	LintProfileUKCryptic = LintProfile{
+		Name:                 "uk-cryptic",
+		RequireSymmetry:      true,
+		MinEntryLen:          3,
+		MaxUncheckedRatio:    0.5,
+		MaxConsecutiveUnches: 1,
+		MaxBlockDensity:      0.40,
+	}
+)
+
+// LintProfileFor picks the default profile for a puzzle type.
+func LintProfileFor(t PuzzleType) LintProfile {
+	switch t {
+	case PuzzleQuick:
+		return LintProfileAmerican
+	default:
+		return LintProfileUKCryptic
+	}
+}
+
+// LintPuzzle reports editorial issues with a puzzle's grid using the
+// profile for its type. Unlike ValidatePuzzle, it judges only grids that
+// are structurally valid; call ValidatePuzzle first.
+func LintPuzzle(p Puzzle) LintReport {
+	return LintPuzzleWithProfile(p, LintProfileFor(p.Type))
+}
+
+func LintPuzzleWithProfile(p Puzzle, prof LintProfile) LintReport {
+	rep := LintReport{Profile: prof.Name, Findings: []LintFinding{}}
+	p.NormalizeGrid()
+	g := p.Grid
+	// The passes index every cell, so a malformed grid (ragged rows
+	// included) gets an empty report; ValidatePuzzle says what is wrong.
+	if g.Rows <= 0 || g.Cols <= 0 || validateGrid(g, g.Rows, g.Cols) != nil {
+		return rep
+	}
+
+	entries := p.Entries
+	if len(entries) == 0 {
+		entries = GenerateEntries(g)
+	}
+
+	if prof.RequireSymmetry {
+		lintSymmetry(&rep, g)
+	}
+	lintEntries(&rep, g, entries, prof)
+	lintIslands(&rep, g)
+	lintDensity(&rep, g, prof)
+
+	return rep
+}
+
+func lintSymmetry(rep *LintReport, g Grid) {
+	// Bars on the outer edge end nothing, so they don't count.
+	right := func(r, c int) bool { return c >= 0 && c < g.Cols-1 && g.Cells[r][c].BarRight }
+	bottom := func(r, c int) bool { return r >= 0 && r < g.Rows-1 && g.Cells[r][c].BarBottom }
+
+	// A cell's image is at (R-r, C-c) under rotation and (r, C-c) under
+	// mirroring. Its right bar lands on the left edge of the image, which is
+	// the right bar of the cell to the image's left; under rotation its
+	// bottom bar likewise becomes the bottom bar of the cell above.
+	R, C := g.Rows-1, g.Cols-1
+	var rot, mirror []CellRef
+	for r := 0; r < g.Rows; r++ {
+		for c := 0; c < g.Cols; c++ {
+			b := g.Cells[r][c].IsBlock
+			if b != g.Cells[R-r][C-c].IsBlock || right(r, c) != right(R-r, C-c-1) || bottom(r, c) != bottom(R-r-1, C-c) {
+				rot = append(rot, CellRef{R: r, C: c})
+			}
+			if b != g.Cells[r][C-c].IsBlock || right(r, c) != right(r, C-c-1) || bottom(r, c) != bottom(r, C-c) {
+				mirror = append(mirror, CellRef{R: r, C: c})
+			}
+		}
+	}
+	if len(rot) > 0 && len(mirror) > 0 {
+		rep.add(LintWarning, LintAsymmetric, rot,
+			"block and bar pattern has neither rotational nor mirror symmetry (%d cells break rotational symmetry)", len(rot))
+	}
+}
+
+func lintEntries(rep *LintReport, g Grid, entries []Entry, prof LintProfile) {
+	// How many entries (across/down) each cell belongs to.
+	owners := make([][]int, g.Rows)
+	for r := range owners {
+		owners[r] = make([]int, g.Cols)
+	}
+	for _, e := range entries {
+		for _, cr := range e.Cells {
+			if cr.R >= 0 && cr.C >= 0 && cr.R < g.Rows && cr.C < g.Cols {
+				owners[cr.R][cr.C]++
+			}
+		}
+	}
+
+	for _, e := range entries {
+		n := len(e.Cells)
+		if n == 0 {
+			continue
+		}
+		if n < prof.MinEntryLen {
+			rep.add(LintError, LintShortEntry, e.Cells,
+				"%d %s has %d cells, minimum is %d", e.Num, e.Dir, n, prof.MinEntryLen)
+		}
+
+		var unches []CellRef
+		run, longest := 0, 0
+		var longestRun []CellRef
+		for i, cr := range e.Cells {
+			if cr.R < 0 || cr.C < 0 || cr.R >= g.Rows || cr.C >= g.Cols {
+				continue
+			}
+			if owners[cr.R][cr.C] < 2 {
+				unches = append(unches, cr)
+				run++
+				if run > longest {
+					longest = run
+					longestRun = e.Cells[i-run+1 : i+1]
+				}
+			} else {
+				run = 0
+			}
+		}
+
+		ratio := float64(len(unches)) / float64(n)
+		if ratio > prof.MaxUncheckedRatio {
+			rep.add(LintWarning, LintUnchecked, unches,
+				"%d %s has %d of %d cells unchecked (max %.0f%%)",
+				e.Num, e.Dir, len(unches), n, prof.MaxUncheckedRatio*100)
+		}
+		if longest > prof.MaxConsecutiveUnches {
+			rep.add(LintWarning, LintConsecutiveUnch, longestRun,
+				"%d %s has %d consecutive unchecked cells (max %d)",
+				e.Num, e.Dir, longest, prof.MaxConsecutiveUnches)
+		}
+	}
+
+	var unused []CellRef
+	for r := 0; r < g.Rows; r++ {
+		for c := 0; c < g.Cols; c++ {
+			if !g.Cells[r][c].IsBlock && owners[r][c] == 0 {
+				unused = append(unused, CellRef{R: r, C: c})
+			}
+		}
+	}
+	if len(unused) > 0 {
+		rep.add(LintError, LintUnusedCell, unused,
+			"%d white cells belong to no entry", len(unused))
+	}
+}
+
+// lintIslands groups white cells into orthogonally connected islands and
+// flags every island other than the largest.
+func lintIslands(rep *LintReport, g Grid) {
+	seen := make([][]bool, g.Rows)
+	for r := range seen {
+		seen[r] = make([]bool, g.Cols)
+	}
+
+	var islands [][]CellRef
+	for r := 0; r < g.Rows; r++ {
+		for c := 0; c < g.Cols; c++ {
+			if g.Cells[r][c].IsBlock || seen[r][c] {
+				continue
+			}
+			var island []CellRef
+			stack := []CellRef{{R: r, C: c}}
+			seen[r][c] = true
+			for len(stack) > 0 {
+				cur := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				island = append(island, cur)
+				for _, d := range [][2]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+					nr, nc := cur.R+d[0], cur.C+d[1]
+					if nr < 0 || nc < 0 || nr >= g.Rows || nc >= g.Cols {
+						continue
+					}
+					if g.Cells[nr][nc].IsBlock || seen[nr][nc] {
+						continue
+					}
+					seen[nr][nc] = true
+					stack = append(stack, CellRef{R: nr, C: nc})
+				}
+			}
+			islands = append(islands, island)
+		}
+	}
+
+	main := 0
+	for i, island := range islands {
+		if len(island) > len(islands[main]) {
+			main = i
+		}
+	}
+	for i, island := range islands {
+		if i == main {
+			continue
+		}
+		rep.add(LintError, LintDisconnected, island,
+			"%d white cells are disconnected from the rest of the grid", len(island))
+	}
+}
+
+func lintDensity(rep *LintReport, g Grid, prof LintProfile) {
+	blocks := 0
+	for r := 0; r < g.Rows; r++ {
+		for c := 0; c < g.Cols; c++ {
+			if g.Cells[r][c].IsBlock {
+				blocks++
+			}
+		}
+	}
+	density := float64(blocks) / float64(g.Rows*g.Cols)
+	if density > prof.MaxBlockDensity {
+		rep.add(LintWarning, LintBlockDensity, nil,
+			"%.0f%% of cells are blocks (max %.0f%%)", density*100, prof.MaxBlockDensity*100)
+	}
+}
@@ -0,0 +1,102 @@
+package domain
+
+import "testing"
+
+func lintCodes(rep LintReport) map[string]int {
+	out := map[string]int{}
+	for _, f := range rep.Findings {
+		out[f.Code]++
+	}
+	return out
+}
+
+func TestLintPuzzle_CleanAmericanGrid(t *testing.T) {
+	g := makeGrid(5, 5, map[[2]int]bool{
+		{0, 0}: true,
+		{4, 4}: true,
+	})
+	p := Puzzle{ID: "p1", Type: PuzzleQuick, Rows: 5, Cols: 5, Grid: g}
+
+	rep := LintPuzzle(p)
+	if rep.Profile != "american" {
+		t.Fatalf("profile=%q want american", rep.Profile)
+	}
+	if len(rep.Findings) != 0 {
+		t.Fatalf("expected no findings, got %+v", rep.Findings)
+	}
+}
+
+func TestLintPuzzle_Findings(t *testing.T) {
+	// Block at (0,0) only breaks symmetry; column 2 blocks split the grid
+	// into two islands, leaving short entries and unchecked cells.
+	g := makeGrid(3, 5, map[[2]int]bool{
+		{0, 0}: true,
+		{0, 2}: true,
+		{1, 2}: true,
+		{2, 2}: true,
+	})
+	p := Puzzle{ID: "p1", Type: PuzzleQuick, Rows: 3, Cols: 5, Grid: g}
+
+	codes := lintCodes(LintPuzzle(p))
+	for _, want := range []string{LintAsymmetric, LintShortEntry, LintDisconnected, LintBlockDensity, LintUnchecked} {
+		if codes[want] == 0 {
+			t.Fatalf("expected %q finding, got %v", want, codes)
+		}
+	}
+}
+
+func TestLintPuzzle_CrypticAllowsAlternateUnches(t *testing.T) {
+	// Classic UK pattern: blocks on every odd/odd cell, so each entry
+	// alternates checked and unchecked cells.
+	blocks := map[[2]int]bool{}
+	for r := 1; r < 5; r += 2 {
+		for c := 1; c < 5; c += 2 {
+			blocks[[2]int{r, c}] = true
+		}
+	}
+	g := makeGrid(5, 5, blocks)
+	p := Puzzle{ID: "p1", Type: PuzzleCryptic, Rows: 5, Cols: 5, Grid: g}
+
+	if codes := lintCodes(LintPuzzle(p)); len(codes) != 0 {
+		t.Fatalf("expected no findings for cryptic grid, got %v", codes)
+	}
+	if codes := lintCodes(LintPuzzleWithProfile(p, LintProfileAmerican)); codes[LintUnchecked] == 0 {
+		t.Fatalf("expected unchecked findings under american profile, got %v", codes)
+	}
+}
+
+func TestLintPuzzle_RaggedGrid(t *testing.T) {
+	g := makeGrid(3, 3, nil)
+	g.Cells[1] = g.Cells[1][:2]
+	p := Puzzle{ID: "p1", Type: PuzzleQuick, Rows: 3, Cols: 3, Grid: g}
+
+	if rep := LintPuzzle(p); len(rep.Findings) != 0 {
+		t.Fatalf("expected an empty report for a ragged grid, got %+v", rep.Findings)
+	}
+}
+
+func TestLintPuzzle_BarSymmetry(t *testing.T) {
+	// On an open 4x4 grid the rotated image of a right bar on (0,0) is a
+	// right bar on (3,2), and its mirror image one on (0,2).
+	cases := []struct {
+		name       string
+		bars       func(g Grid)
+		asymmetric bool
+	}{
+		{"none", func(g Grid) {}, false},
+		{"lone bar", func(g Grid) { g.Cells[0][0].BarRight = true }, true},
+		{"rotated", func(g Grid) { g.Cells[0][0].BarRight = true; g.Cells[3][2].BarRight = true }, false},
+		{"mirrored", func(g Grid) { g.Cells[0][0].BarRight = true; g.Cells[0][2].BarRight = true }, false},
+		{"lone bottom bar", func(g Grid) { g.Cells[0][1].BarBottom = true }, true},
+		{"rotated bottom", func(g Grid) { g.Cells[0][1].BarBottom = true; g.Cells[2][2].BarBottom = true }, false},
+		{"outer edge", func(g Grid) { g.Cells[1][3].BarRight = true; g.Cells[3][0].BarBottom = true }, false},
+	}
+	for _, tc := range cases {
+		g := makeGrid(4, 4, nil)
+		tc.bars(g)
+		p := Puzzle{ID: "p1", Type: PuzzleQuick, Rows: 4, Cols: 4, Grid: g}
+		if got := lintCodes(LintPuzzleWithProfile(p, LintProfileAmerican))[LintAsymmetric] > 0; got != tc.asymmetric {
+			t.Errorf("%s: asymmetric=%v want %v", tc.name, got, tc.asymmetric)
+		}
+	}
+}
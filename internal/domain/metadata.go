@@ -0,0 +1,116 @@
+package domain
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Field length limits, in characters.
+const (
+	maxTitleLen      = 200
+	maxAuthorLen     = 100
+	maxCopyrightLen  = 200
+	maxDifficultyLen = 32
+	maxPreambleLen   = 4000
+	maxNotesLen      = 4000
+)
+
+// richTags are the only HTML tags allowed in the preamble and notes, which
+// setters use for italics and the odd line break. Attributes aren't allowed.
+var richTags = map[string]bool{
+	"b": true, "i": true, "em": true, "strong": true,
+	"sub": true, "sup": true, "br": true,
+}
+
+func validateMetadata(p Puzzle) *ValidationError {
+	var verr ValidationError
+
+	plain := []struct {
+		name  string
+		value string
+		max   int
+	}{
+		{"title", p.Title, maxTitleLen},
+		{"author", p.Author, maxAuthorLen},
+		{"copyright", p.Copyright, maxCopyrightLen},
+		{"difficulty", p.Difficulty, maxDifficultyLen},
+	}
+	for _, f := range plain {
+		checkTextLen(&verr, f.name, f.value, f.max)
+		if strings.ContainsAny(f.value, "<>") {
+			verr.add(f.name, "%s must be plain text (no markup)", f.name)
+		}
+		if hasControl(f.value, false) {
+			verr.add(f.name, "%s must be a single line without control characters", f.name)
+		}
+	}
+
+	rich := []struct {
+		name  string
+		value string
+		max   int
+	}{
+		{"preamble", p.Preamble, maxPreambleLen},
+		{"notes", p.Notes, maxNotesLen},
+	}
+	for _, f := range rich {
+		checkTextLen(&verr, f.name, f.value, f.max)
+		if tag, ok := checkMarkup(f.value); !ok {
+			verr.add(f.name, "%s contains disallowed markup %q (allowed: b, i, em, strong, sub, sup, br)", f.name, tag)
+		}
+		if hasControl(f.value, true) {
+			verr.add(f.name, "%s contains control characters", f.name)
+		}
+	}
+
+	if verr.ok() {
+		return nil
+	}
+	return &verr
+}
+
+func checkTextLen(verr *ValidationError, name, value string, max int) {
+	if n := utf8.RuneCountInString(value); n > max {
+		verr.add(name, "%s is %d characters, max %d", name, n, max)
+	}
+}
+
+// hasControl reports whether s contains control characters. Newlines and
+// tabs are allowed in multi-line fields.
+func hasControl(s string, multiline bool) bool {
+	for _, r := range s {
+		if multiline && (r == '\n' || r == '\r' || r == '\t') {
+			continue
+		}
+		if unicode.IsControl(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkMarkup scans s for tags and returns the first one not in richTags.
+// A '<' or '>' that isn't part of a well-formed tag is also rejected.
+func checkMarkup(s string) (string, bool) {
+	for {
+		i := strings.IndexAny(s, "<>")
+		if i < 0 {
+			return "", true
+		}
+		if s[i] == '>' {
+			return ">", false
+		}
+		j := strings.IndexByte(s[i:], '>')
+		if j < 0 {
+			return s[i:], false
+		}
+		tag := s[i : i+j+1]
+		name := strings.TrimSpace(strings.Trim(tag, "<>"))
+		name = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(name, "/"), "/"))
+		if !richTags[strings.ToLower(name)] {
+			return tag, false
+		}
+		s = s[i+j+1:]
+	}
+}
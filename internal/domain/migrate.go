@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+)
+
+// MigrationConflict describes a filled cell that couldn't be carried onto a
+// new puzzle revision.
+type MigrationConflict struct {
+	Cell   string `json:"cell"` // "r,c"
+	Value  string `json:"value"`
+	Reason string `json:"reason"`
+}
+
+// SameGeometry reports whether two puzzles have the same dimensions, blocks
+// and bars, so a fill for one fits the other cell for cell.
+func SameGeometry(a, b Puzzle) bool {
+	if a.Rows != b.Rows || a.Cols != b.Cols || len(a.Grid.Cells) != len(b.Grid.Cells) {
+		return false
+	}
+	for r := range a.Grid.Cells {
+		if len(a.Grid.Cells[r]) != len(b.Grid.Cells[r]) {
+			return false
+		}
+		for c := range a.Grid.Cells[r] {
+			ca, cb := a.Grid.Cells[r][c], b.Grid.Cells[r][c]
+			if ca.IsBlock != cb.IsBlock || ca.BarRight != cb.BarRight || ca.BarBottom != cb.BarBottom {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// MigrateFill carries a session's fill from one revision of its puzzle onto
+// another. When the geometry is unchanged every value carries over.
+// Otherwise values in cells that are still white carry over and the rest are
+// reported as conflicts; so are values in a cell whose across or down entry
+// has changed shape, since the word through it is no longer the one the
+// solver was writing. A value in a cell that has become given is replaced by
+// the given letter, and reported unless the two agree. Every conflicting
+// value is left out of the returned fill.
+func MigrateFill(from, to Puzzle, fill map[string]string) (map[string]string, []MigrationConflict) {
+	from.NormalizeGrid()
+	to.NormalizeGrid()
+	var fromEntries, toEntries map[CellRef][]string
+	if !SameGeometry(from, to) {
+		fromEntries, toEntries = entryShapes(from), entryShapes(to)
+	}
+
+	out := map[string]string{}
+	var conflicts []MigrationConflict
+
+	for key, v := range fill {
+		if NormalizeAnswer(v) == "" {
+			continue
+		}
+		cr, err := ParseCellKey(key)
+		if err != nil {
+			conflicts = append(conflicts, MigrationConflict{Cell: key, Value: v, Reason: "invalid cell key"})
+			continue
+		}
+		if !to.Grid.IsWhite(cr.R, cr.C) {
+			reason := "cell is now a block"
+			if cr.R >= to.Grid.Rows || cr.C >= to.Grid.Cols {
+				reason = "cell is outside the grid"
+			}
+			conflicts = append(conflicts, MigrationConflict{Cell: key, Value: v, Reason: reason})
+			continue
+		}
+		cell := to.Grid.Cells[cr.R][cr.C]
+		if cell.IsGiven && !CellValueMatches(cell.Solution, v) {
+			conflicts = append(conflicts, MigrationConflict{Cell: key, Value: v, Reason: "cell is now given"})
+			continue
+		}
+		if toEntries != nil && !slices.Equal(fromEntries[cr], toEntries[cr]) {
+			conflicts = append(conflicts, MigrationConflict{Cell: key, Value: v, Reason: "entry through cell has changed"})
+			continue
+		}
+		out[key] = v
+	}
+
+	return ApplyGivens(to, out), conflicts
+}
+
+// entryShapes maps each cell to the shapes (direction, start and length) of
+// the entries through it, sorted. Supplied entries are used if there are
+// any, otherwise the ones the grid implies.
+func entryShapes(p Puzzle) map[CellRef][]string {
+	entries := p.Entries
+	if len(entries) == 0 {
+		entries = GenerateEntries(p.Grid)
+	}
+	out := map[CellRef][]string{}
+	for _, e := range entries {
+		if len(e.Cells) == 0 {
+			continue
+		}
+		shape := fmt.Sprintf("%s@%d,%d+%d", e.Dir, e.Cells[0].R, e.Cells[0].C, len(e.Cells))
+		for _, cr := range e.Cells {
+			out[cr] = append(out[cr], shape)
+		}
+	}
+	for _, shapes := range out {
+		slices.Sort(shapes)
+	}
+	return out
+}
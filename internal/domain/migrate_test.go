@@ -0,0 +1,57 @@
+package domain
+
+import "testing"
+
+func TestMigrateFill(t *testing.T) {
+	from := solvedPuzzle()
+	fill := map[string]string{"0,0": "C", "0,1": "A", "1,0": "O", "2,2": "T"}
+
+	// Same geometry, clue text changed: everything carries.
+	to := from.Clone()
+	to.Revision = 2
+	if !SameGeometry(from, to) {
+		t.Fatalf("expected same geometry")
+	}
+	out, conflicts := MigrateFill(from, to, fill)
+	if len(conflicts) != 0 || len(out) != len(fill) {
+		t.Fatalf("out=%v conflicts=%v", out, conflicts)
+	}
+
+	// (0,1) becomes a block and (2,2) becomes given with a different letter.
+	to.Grid.Cells[0][1] = Cell{R: 0, C: 1, IsBlock: true}
+	to.Grid.Cells[2][2].IsGiven = true
+	to.Grid.Cells[2][2].Solution = "S"
+	if SameGeometry(from, to) {
+		t.Fatalf("expected different geometry")
+	}
+	out, conflicts = MigrateFill(from, to, fill)
+	if len(conflicts) != 2 {
+		t.Fatalf("conflicts=%v want 2", conflicts)
+	}
+	if _, ok := out["0,1"]; ok || out["2,2"] != "S" || out["0,0"] != "C" {
+		t.Fatalf("out=%v", out)
+	}
+}
+
+func TestMigrateFill_EntryChanged(t *testing.T) {
+	from := solvedPuzzle()
+	fill := map[string]string{"0,0": "C", "0,1": "A", "1,0": "O", "2,2": "T"}
+
+	// A bar after (0,0) keeps every cell white but splits 1 across, so the
+	// words through (0,0) and (0,1) are no longer the ones filled in.
+	to := from.Clone()
+	to.Grid.Cells[0][0].BarRight = true
+	to.Entries = GenerateEntries(to.Grid)
+
+	out, conflicts := MigrateFill(from, to, fill)
+	if len(out) != 2 || out["1,0"] != "O" || out["2,2"] != "T" {
+		t.Fatalf("out=%v, want only the values off the changed entry", out)
+	}
+	got := map[string]bool{}
+	for _, c := range conflicts {
+		got[c.Cell] = true
+	}
+	if len(conflicts) != 2 || !got["0,0"] || !got["0,1"] {
+		t.Fatalf("conflicts=%v, want 0,0 and 0,1", conflicts)
+	}
+}
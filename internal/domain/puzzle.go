@@ -1,5 +1,7 @@
 package domain
 
+import "time"
+
 type PuzzleType string
 
 const (
@@ -8,13 +10,84 @@ const (
 	PuzzleMixed   PuzzleType = "mixed"
 )
 
+// DateLayout is the format of publication dates in the API.
+const DateLayout = "2006-01-02"
+
 type Puzzle struct {
-	ID     string
-	Title  string
-	Type   PuzzleType
-	Rows   int
-	Cols   int
-	Grid   Grid
-	Entries []Entry
-	Clues   []Clue
+	ID      string     `json:"id"`
+	Title   string     `json:"title"`
+	Type    PuzzleType `json:"type"`
+	Rows    int        `json:"rows"`
+	Cols    int        `json:"cols"`
+	Grid    Grid       `json:"grid"`
+	Entries []Entry    `json:"entries"`
+	Clues   []Clue     `json:"clues"`
+
+	// Revision numbers a stored puzzle's immutable versions, starting at 1.
+	// It is assigned by the store, and doubles as the puzzle's version: it
+	// is served as the ETag that If-Match on edits is compared against.
+	Revision int `json:"revision"`
+
+	// CreatedAt is when the puzzle's first revision was stored; later
+	// revisions keep it. Assigned by the store, which lists puzzles by it.
+	CreatedAt time.Time `json:"createdAt,omitzero"`
+
+	// Metadata. All optional; see validateMetadata for limits.
+	Author      string `json:"author,omitempty"`     // setter's name or pseudonym
+	PublishedOn Date   `json:"publishedOn,omitzero"` // publication date, zero if unset
+	Copyright   string `json:"copyright,omitempty"`
+	Difficulty  string `json:"difficulty,omitempty"` // free-form label, e.g. "easy" or "***"
+	Preamble    string `json:"preamble,omitempty"`   // special instructions for themed puzzles
+	Notes       string `json:"notes,omitempty"`      // setter's or editor's notes shown with the puzzle
+}
+
+// NormalizeGrid fills in Grid.Rows and Grid.Cols from the puzzle's Rows and
+// Cols where the grid leaves them at zero, as puzzle JSON usually does.
+// Dimensions the grid does set are kept, so ValidatePuzzle can report a
+// mismatch. Functions taking a Puzzle apply it themselves; ingestion applies
+// it so stored puzzles carry the grid's dimensions.
+func (p *Puzzle) NormalizeGrid() {
+	if p.Grid.Rows == 0 {
+		p.Grid.Rows = p.Rows
+	}
+	if p.Grid.Cols == 0 {
+		p.Grid.Cols = p.Cols
+	}
+}
+
+// Clone returns a deep copy of the puzzle, sharing no slices or pointers
+// with the original.
+func (p Puzzle) Clone() Puzzle {
+	out := p
+	out.Grid = cloneGrid(p.Grid)
+	for r, row := range out.Grid.Cells {
+		for c, cell := range row {
+			if cell.Annotation != nil {
+				a := *cell.Annotation
+				out.Grid.Cells[r][c].Annotation = &a
+			}
+		}
+	}
+
+	if p.Entries != nil {
+		out.Entries = make([]Entry, len(p.Entries))
+		for i, e := range p.Entries {
+			out.Entries[i] = e
+			out.Entries[i].Cells = append([]CellRef(nil), e.Cells...)
+		}
+	}
+
+	if p.Clues != nil {
+		out.Clues = make([]Clue, len(p.Clues))
+		for i, c := range p.Clues {
+			out.Clues[i] = c
+			out.Clues[i].LinkedEntryIDs = append([]string(nil), c.LinkedEntryIDs...)
+			out.Clues[i].Tags = append([]string(nil), c.Tags...)
+			if c.Explanation != nil {
+				ex := *c.Explanation
+				out.Clues[i].Explanation = &ex
+			}
+		}
+	}
+	return out
 }
@@ -0,0 +1,114 @@
+package domain
+
+import "strconv"
+
+type entryStart struct {
+	r, c int
+	dir  Direction
+}
+
+func startOf(e Entry) entryStart {
+	return entryStart{r: e.Cells[0].R, c: e.Cells[0].C, dir: e.Dir}
+}
+
+// validateGeometry compares supplied entries with the set GenerateEntries
+// implies for the grid, matching them by starting cell and direction. The
+// grid must already be known to be well-formed.
+func validateGeometry(g Grid, entries []Entry) *ValidationError {
+	var verr ValidationError
+
+	want := GenerateEntries(g)
+	wantByStart := make(map[entryStart]Entry, len(want))
+	for _, e := range want {
+		wantByStart[startOf(e)] = e
+	}
+
+	supplied := map[entryStart]bool{}
+	for i, e := range entries {
+		if len(e.Cells) == 0 {
+			continue
+		}
+		st := startOf(e)
+		supplied[st] = true
+
+		w, ok := wantByStart[st]
+		if !ok {
+			verr.add(entryField(i, ""), "entry[%d] extra: %d %s at (%d,%d) is not an entry in the grid",
+				i, e.Num, e.Dir, st.r, st.c)
+			continue
+		}
+		if n := len(e.Cells); n < len(w.Cells) {
+			verr.add(entryField(i, "cells"), "entry[%d] truncated: %d %s has %d cells, grid implies %d",
+				i, e.Num, e.Dir, n, len(w.Cells))
+		} else if n > len(w.Cells) {
+			verr.add(entryField(i, "cells"), "entry[%d] overruns: %d %s has %d cells, grid implies %d",
+				i, e.Num, e.Dir, n, len(w.Cells))
+		}
+		if e.Num != w.Num {
+			verr.add(entryField(i, "num"), "entry[%d] misnumbered: %d %s at (%d,%d) should be %d %s",
+				i, e.Num, e.Dir, st.r, st.c, w.Num, w.Dir)
+		}
+	}
+
+	for _, w := range want {
+		st := startOf(w)
+		if !supplied[st] {
+			verr.add("entries", "missing entry: %d %s at (%d,%d) (%d cells)", w.Num, w.Dir, st.r, st.c, len(w.Cells))
+		}
+	}
+
+	if verr.ok() {
+		return nil
+	}
+	return &verr
+}
+
+// ReconcileEntries returns the entries the grid implies, correctly numbered,
+// carrying over ID, Enum and Answer from supplied entries that start at the
+// same cell in the same direction. Enum and Answer are dropped when the
+// entry's length has changed. Supplied entries with no counterpart in the
+// grid are discarded.
+//
+// Carried-over IDs are kept even if the entry's number changed, so clues
+// stay linked across grid edits. New entries get their canonical ID, or a
+// suffixed one ("3a-2") if a surviving entry already holds it.
+func ReconcileEntries(g Grid, entries []Entry) []Entry {
+	prev := make(map[entryStart]Entry, len(entries))
+	for _, e := range entries {
+		if len(e.Cells) > 0 {
+			prev[startOf(e)] = e
+		}
+	}
+
+	out := GenerateEntries(g)
+	used := map[string]bool{}
+	carried := make([]bool, len(out))
+	for i, e := range out {
+		p, ok := prev[startOf(e)]
+		if !ok {
+			continue
+		}
+		if p.ID != "" && !used[p.ID] {
+			out[i].ID = p.ID
+			used[p.ID] = true
+			carried[i] = true
+		}
+		if len(p.Cells) == len(e.Cells) {
+			out[i].Enum = p.Enum
+			out[i].Answer = p.Answer
+		}
+	}
+
+	for i, e := range out {
+		if carried[i] {
+			continue
+		}
+		id := e.ID
+		for n := 2; used[id]; n++ {
+			id = e.ID + "-" + strconv.Itoa(n)
+		}
+		out[i].ID = id
+		used[id] = true
+	}
+	return out
+}
@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidatePuzzle_EntryGeometry(t *testing.T) {
+	g := makeGrid(3, 3, map[[2]int]bool{
+		{1, 1}: true,
+	})
+	good := GenerateEntries(g)
+
+	tests := []struct {
+		name   string
+		mutate func([]Entry) []Entry
+		want   string
+	}{
+		{"missing", func(es []Entry) []Entry { return es[1:] }, "missing entry"},
+		{"extra", func(es []Entry) []Entry {
+			return append(es, Entry{Dir: Across, Num: 9, Cells: []CellRef{{1, 2}}})
+		}, "extra"},
+		{"truncated", func(es []Entry) []Entry {
+			es[0].Cells = es[0].Cells[:2]
+			return es
+		}, "truncated"},
+		{"misnumbered", func(es []Entry) []Entry {
+			es[0].Num, es[1].Num = 2, 1
+			return es
+		}, "misnumbered"},
+	}
+
+	for _, tt := range tests {
+		entries := tt.mutate(cloneEntries(good))
+		p := Puzzle{ID: "p1", Title: "Test", Type: PuzzleQuick, Rows: 3, Cols: 3, Grid: g, Entries: entries}
+
+		err := ValidatePuzzle(p)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Fatalf("%s: expected %q problem, got %v", tt.name, tt.want, err)
+		}
+
+		fixed := p
+		fixed.Entries = ReconcileEntries(g, entries)
+		if err := ValidatePuzzle(fixed); err != nil {
+			t.Fatalf("%s: reconciled entries still invalid: %v", tt.name, err)
+		}
+	}
+}
+
+func TestReconcileEntries_KeepsAnswers(t *testing.T) {
+	g := makeGrid(3, 3, map[[2]int]bool{
+		{1, 1}: true,
+	})
+	entries := cloneEntries(GenerateEntries(g))
+	entries[0].ID = "x"
+	entries[0].Answer = "CAT"
+	entries[0].Num = 7
+	entries[1].Answer = "COW"
+	entries[1].Cells = entries[1].Cells[:2]
+
+	out := ReconcileEntries(g, entries)
+	if out[0].ID != "x" || out[0].Answer != "CAT" || out[0].Num != 1 {
+		t.Fatalf("entry 0 not carried over: %+v", out[0])
+	}
+	if out[1].Answer != "" || len(out[1].Cells) != 3 {
+		t.Fatalf("entry 1 should be regenerated without answer: %+v", out[1])
+	}
+}
+
+func cloneEntries(es []Entry) []Entry {
+	out := make([]Entry, len(es))
+	for i, e := range es {
+		out[i] = e
+		out[i].Cells = append([]CellRef(nil), e.Cells...)
+	}
+	return out
+}
+
+func TestReconcileEntries_StableIDs(t *testing.T) {
+	g := makeGrid(3, 4, nil)
+	before := GenerateEntries(g)
+
+	// Blocking (0,0) renumbers everything; entries that still start in the
+	// same place keep their old IDs.
+	g.Cells[0][0].IsBlock = true
+	g.Cells[2][3].IsBlock = true
+	after := ReconcileEntries(g, before)
+
+	byStart := map[entryStart]string{}
+	for _, e := range before {
+		byStart[startOf(e)] = e.ID
+	}
+	seen := map[string]bool{}
+	for _, e := range after {
+		if old, ok := byStart[startOf(e)]; ok && e.ID != old {
+			t.Fatalf("%d %s: id %q, want carried-over %q", e.Num, e.Dir, e.ID, old)
+		}
+		if seen[e.ID] {
+			t.Fatalf("duplicate id %q in %+v", e.ID, after)
+		}
+		seen[e.ID] = true
+	}
+}
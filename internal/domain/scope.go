@@ -0,0 +1,52 @@
+package domain
+
+import "fmt"
+
+// Scope selects the cells a check or reveal applies to.
+type Scope string
+
+const (
+	ScopeCell  Scope = "cell"
+	ScopeEntry Scope = "entry"
+	ScopeGrid  Scope = "grid"
+)
+
+// ScopeCells resolves a scope to the white cells it covers, in reading
+// order for the grid and entry order for an entry. cell ("r,c") is used for
+// ScopeCell and entryID for ScopeEntry.
+func ScopeCells(p Puzzle, scope Scope, cell, entryID string) ([]CellRef, error) {
+	p.NormalizeGrid()
+	switch scope {
+	case ScopeCell:
+		cr, err := ParseCellKey(cell)
+		if err != nil {
+			return nil, err
+		}
+		if !p.Grid.IsWhite(cr.R, cr.C) {
+			return nil, fmt.Errorf("cell %q is not a white cell of the grid", cell)
+		}
+		return []CellRef{cr}, nil
+
+	case ScopeEntry:
+		for _, e := range p.Entries {
+			if e.ID == entryID {
+				return append([]CellRef(nil), e.Cells...), nil
+			}
+		}
+		return nil, fmt.Errorf("unknown entry %q", entryID)
+
+	case ScopeGrid:
+		var out []CellRef
+		for r, row := range p.Grid.Cells {
+			for c, cl := range row {
+				if !cl.IsBlock {
+					out = append(out, CellRef{R: r, C: c})
+				}
+			}
+		}
+		return out, nil
+
+	default:
+		return nil, fmt.Errorf("unknown scope %q (want cell, entry or grid)", scope)
+	}
+}
@@ -0,0 +1,32 @@
+package domain
+
+import "testing"
+
+func TestScopeCells(t *testing.T) {
+	p := solvedPuzzle()
+
+	tests := []struct {
+		scope       Scope
+		cell, entry string
+		want        int
+		wantOk      bool
+	}{
+		{ScopeCell, "0,2", "", 1, true},
+		{ScopeCell, "1,1", "", 0, false}, // block
+		{ScopeCell, "5,5", "", 0, false},
+		{ScopeEntry, "", "1d", 3, true},
+		{ScopeEntry, "", "9a", 0, false},
+		{ScopeGrid, "", "", 8, true},
+		{"row", "", "", 0, false},
+	}
+
+	for _, tt := range tests {
+		cells, err := ScopeCells(p, tt.scope, tt.cell, tt.entry)
+		if tt.wantOk && (err != nil || len(cells) != tt.want) {
+			t.Fatalf("ScopeCells(%s,%q,%q)=%v,%v want %d cells", tt.scope, tt.cell, tt.entry, cells, err, tt.want)
+		}
+		if !tt.wantOk && err == nil {
+			t.Fatalf("ScopeCells(%s,%q,%q) expected error", tt.scope, tt.cell, tt.entry)
+		}
+	}
+}
@@ -1,6 +1,14 @@
 package domain
 
-import "time"
+import (
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
 
 type SolveSession struct {
 	ID        string    `json:"id"`
@@ -8,13 +16,141 @@ type SolveSession struct {
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
 
+	// Version goes up by one on every write. It is served as the session's
+	// ETag so clients can make conditional writes with If-Match.
+	Version int `json:"version"`
+
+	// PuzzleRevision pins the session to the puzzle revision it is being
+	// solved against (see MigrateFill for moving to a newer one).
+	PuzzleRevision int `json:"puzzleRevision"`
+
 	// GridState stores the user's current fill.
-	// Key format: "r,c" -> cell value (single letter or empty string)
+	// Key format: "r,c" (see CellKey) -> cell value. A value is normally a
+	// single letter; rebus cells hold several ("TH"). Empty means unfilled.
+	// Values are compared with CellValueMatches, so case and punctuation
+	// don't matter. Given cells always hold their given letter (see
+	// ApplyGivens).
 	GridState map[string]string `json:"gridState"`
 
 	// Pencil marks (optional MVP)
 	Pencil map[string]bool `json:"pencil"`
 
+	// Checked marks cells whose fill has been checked, keyed like GridState.
+	Checked map[string]bool `json:"checked"`
+
+	// Revealed marks cells whose solution was revealed, keyed like GridState.
+	Revealed map[string]bool `json:"revealed"`
+
 	ChecksUsed  int `json:"checksUsed"`
 	RevealsUsed int `json:"revealsUsed"`
+
+	// CompletedAt is when the fill first matched every solution (see
+	// MarkCompleted); zero until then. SolvedClean records that no checks or
+	// reveals were used by that point.
+	CompletedAt time.Time `json:"completedAt,omitzero"`
+	SolvedClean bool      `json:"solvedClean"`
+
+	// The solve timer is kept by the server. TimerElapsedMs is the time
+	// banked before the last pause; TimerRunningSince is when the clock was
+	// last started, zero while paused or once completed. See SolveTime.
+	TimerElapsedMs    int64     `json:"timerElapsedMs"`
+	TimerRunningSince time.Time `json:"timerRunningSince,omitzero"`
+}
+
+// Clone returns a copy of the session sharing no maps with the original.
+func (s SolveSession) Clone() SolveSession {
+	out := s
+	out.GridState = maps.Clone(s.GridState)
+	out.Pencil = maps.Clone(s.Pencil)
+	out.Checked = maps.Clone(s.Checked)
+	out.Revealed = maps.Clone(s.Revealed)
+	return out
+}
+
+// CellKey formats the GridState key for a cell.
+func CellKey(r, c int) string {
+	return strconv.Itoa(r) + "," + strconv.Itoa(c)
+}
+
+// ParseCellKey parses a GridState key of the form "r,c".
+func ParseCellKey(key string) (CellRef, error) {
+	rs, cs, ok := strings.Cut(key, ",")
+	if !ok {
+		return CellRef{}, fmt.Errorf("cell key %q is not of the form r,c", key)
+	}
+	r, err := strconv.Atoi(strings.TrimSpace(rs))
+	if err != nil || r < 0 {
+		return CellRef{}, fmt.Errorf("cell key %q has invalid row", key)
+	}
+	c, err := strconv.Atoi(strings.TrimSpace(cs))
+	if err != nil || c < 0 {
+		return CellRef{}, fmt.Errorf("cell key %q has invalid column", key)
+	}
+	return CellRef{R: r, C: c}, nil
+}
+
+// CellValueMatches reports whether a filled cell value matches a cell's
+// solution. Both are normalised with NormalizeAnswer, and a rebus cell must
+// be filled with all of its letters.
+func CellValueMatches(solution, value string) bool {
+	want := NormalizeAnswer(solution)
+	return want != "" && want == NormalizeAnswer(value)
+}
+
+// ApplyGivens returns a copy of a fill with every given cell set to its
+// given letter, discarding whatever the client wrote there.
+func ApplyGivens(p Puzzle, state map[string]string) map[string]string {
+	out := make(map[string]string, len(state))
+	for k, v := range state {
+		out[k] = v
+	}
+	for r, row := range p.Grid.Cells {
+		for c, cell := range row {
+			if cell.IsGiven && !cell.IsBlock {
+				out[CellKey(r, c)] = NormalizeAnswer(cell.Solution)
+			}
+		}
+	}
+	return out
+}
+
+// MaxCellValueLen is the most letters a solver may write into one cell.
+// It is the same for every cell so that it doesn't give away which cells
+// are rebus cells.
+const MaxCellValueLen = 8
+
+// WritableCell parses a GridState key and checks that a solver may write to
+// that cell: it must be a white cell of the puzzle that isn't given.
+func WritableCell(p Puzzle, key string) (CellRef, error) {
+	p.NormalizeGrid()
+	cr, err := ParseCellKey(key)
+	if err != nil {
+		return CellRef{}, err
+	}
+	if cr.R >= p.Rows || cr.C >= p.Cols {
+		return CellRef{}, fmt.Errorf("cell %s is outside the %dx%d grid", key, p.Rows, p.Cols)
+	}
+	if !p.Grid.IsWhite(cr.R, cr.C) {
+		return CellRef{}, fmt.Errorf("cell %s is a block", key)
+	}
+	if p.Grid.Cells[cr.R][cr.C].IsGiven {
+		return CellRef{}, fmt.Errorf("cell %s is given", key)
+	}
+	return cr, nil
+}
+
+// NormalizeCellValue checks a value a solver wants to write into a cell and
+// returns it upper-cased. Values may only contain letters and digits, up to
+// MaxCellValueLen of them; an empty value clears the cell.
+func NormalizeCellValue(value string) (string, error) {
+	v := strings.TrimSpace(value)
+	for _, r := range v {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return "", fmt.Errorf("value %q may only contain letters and digits", value)
+		}
+	}
+	if n := utf8.RuneCountInString(v); n > MaxCellValueLen {
+		return "", fmt.Errorf("value %q is %d letters, max %d", value, n, MaxCellValueLen)
+	}
+	return strings.ToUpper(v), nil
 }
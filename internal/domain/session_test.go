@@ -0,0 +1,109 @@
+package domain
+
+import "testing"
+
+func TestParseCellKey(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   CellRef
+		wantOk bool
+	}{
+		{"0,0", CellRef{0, 0}, true},
+		{"3,12", CellRef{3, 12}, true},
+		{" 1 , 2 ", CellRef{1, 2}, true},
+		{"1", CellRef{}, false},
+		{"-1,2", CellRef{}, false},
+		{"a,b", CellRef{}, false},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseCellKey(tt.in)
+		if tt.wantOk && (err != nil || got != tt.want) {
+			t.Fatalf("ParseCellKey(%q)=%v,%v want %v", tt.in, got, err, tt.want)
+		}
+		if !tt.wantOk && err == nil {
+			t.Fatalf("ParseCellKey(%q) expected error, got %v", tt.in, got)
+		}
+		if tt.wantOk && CellKey(got.R, got.C) != CellKey(tt.want.R, tt.want.C) {
+			t.Fatalf("CellKey round trip failed for %q", tt.in)
+		}
+	}
+}
+
+func TestApplyGivens(t *testing.T) {
+	g := makeGrid(2, 2, nil)
+	g.Cells[0][1].IsGiven = true
+	g.Cells[0][1].Solution = "q"
+	p := Puzzle{ID: "p1", Rows: 2, Cols: 2, Grid: g}
+
+	pub := ToPublic(p)
+	if pub.Grid.Cells[0][1].Letter != "Q" || pub.Grid.Cells[0][0].Letter != "" {
+		t.Fatalf("given letter not published correctly: %+v", pub.Grid.Cells[0])
+	}
+
+	in := map[string]string{"0,0": "A", "0,1": "X"}
+	out := ApplyGivens(p, in)
+	if out["0,0"] != "A" || out["0,1"] != "Q" {
+		t.Fatalf("ApplyGivens=%v", out)
+	}
+	if in["0,1"] != "X" {
+		t.Fatalf("ApplyGivens modified its input")
+	}
+}
+
+func TestCellValueMatches(t *testing.T) {
+	tests := []struct {
+		solution, value string
+		want            bool
+	}{
+		{"A", "a", true},
+		{"TH", "th", true},
+		{"TH", "T", false},
+		{"A", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		if got := CellValueMatches(tt.solution, tt.value); got != tt.want {
+			t.Fatalf("CellValueMatches(%q, %q)=%v want=%v", tt.solution, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestWritableCell(t *testing.T) {
+	g := makeGrid(2, 2, map[[2]int]bool{{1, 1}: true})
+	g.Cells[0][1].IsGiven = true
+	g.Cells[0][1].Solution = "Q"
+	p := Puzzle{ID: "p1", Rows: 2, Cols: 2, Grid: g}
+
+	if cr, err := WritableCell(p, "1,0"); err != nil || cr != (CellRef{R: 1, C: 0}) {
+		t.Fatalf("WritableCell(1,0)=%v, %v", cr, err)
+	}
+	for _, key := range []string{"0,1", "1,1", "2,0", "0,-1", "x"} {
+		if _, err := WritableCell(p, key); err == nil {
+			t.Fatalf("WritableCell(%q): expected error", key)
+		}
+	}
+}
+
+func TestNormalizeCellValue(t *testing.T) {
+	tests := []struct {
+		in, want string
+		ok       bool
+	}{
+		{"a", "A", true},
+		{"th", "TH", true},
+		{"", "", true},
+		{"7", "7", true},
+		{"A-", "", false},
+		{"?", "", false},
+		{"ABCDEFGHI", "", false},
+	}
+
+	for _, tt := range tests {
+		got, err := NormalizeCellValue(tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Fatalf("NormalizeCellValue(%q)=%q, %v want=%q ok=%v", tt.in, got, err, tt.want, tt.ok)
+		}
+	}
+}
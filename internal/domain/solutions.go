@@ -0,0 +1,150 @@
+package domain
+
+import "fmt"
+
+// entryLabel names an entry in validation problems, e.g. "5 across (5a)".
+func entryLabel(e Entry) string {
+	if e.ID != "" {
+		return fmt.Sprintf("%d %s (%s)", e.Num, e.Dir, e.ID)
+	}
+	return fmt.Sprintf("%d %s", e.Num, e.Dir)
+}
+
+// validateSolutions checks that entry answers agree with cell solutions and
+// with each other wherever two entries cross. Entries whose answer doesn't
+// fit their cells are skipped; validateEntries reports those.
+func validateSolutions(g Grid, entries []Entry) *ValidationError {
+	var verr ValidationError
+
+	type claim struct {
+		entry   Entry
+		letters string
+	}
+	claims := map[CellRef]claim{}
+
+	for i, e := range entries {
+		if e.Answer == "" {
+			continue
+		}
+		parts, ok := g.SplitAnswer(e.Cells, e.Answer)
+		if !ok {
+			continue
+		}
+		for j, cr := range e.Cells {
+			if cr.R < 0 || cr.C < 0 || cr.R >= g.Rows || cr.C >= g.Cols {
+				continue
+			}
+			if sol := g.Cells[cr.R][cr.C].Solution; sol != "" && NormalizeAnswer(sol) != parts[j] {
+				verr.add(entryField(i, "answer"), "%s has %q at (%d,%d) but the cell solution is %q",
+					entryLabel(e), parts[j], cr.R, cr.C, sol)
+			}
+			prev, ok := claims[cr]
+			if !ok {
+				claims[cr] = claim{entry: e, letters: parts[j]}
+				continue
+			}
+			if prev.letters != parts[j] {
+				verr.add(entryField(i, "answer"), "crossing conflict at (%d,%d): %s has %q, %s has %q",
+					cr.R, cr.C, entryLabel(prev.entry), prev.letters, entryLabel(e), parts[j])
+			}
+		}
+	}
+
+	if verr.ok() {
+		return nil
+	}
+	return &verr
+}
+
+// FillCellsFromAnswers returns a copy of the puzzle with every unsolved cell
+// given the letters the crossing entry answers put there. Cells that already
+// have a solution keep it. Rebus cells must already carry their solution,
+// since an answer alone doesn't say how its letters split between cells.
+//
+// The result is checked with the same rules as ValidatePuzzle; conflicts are
+// returned as a ValidationError alongside the filled puzzle.
+func FillCellsFromAnswers(p Puzzle) (Puzzle, error) {
+	p.NormalizeGrid()
+	out := p
+	out.Grid = cloneGrid(p.Grid)
+	g := out.Grid
+
+	var verr ValidationError
+	for i, e := range p.Entries {
+		if e.Answer == "" {
+			continue
+		}
+		parts, ok := g.SplitAnswer(e.Cells, e.Answer)
+		if !ok {
+			verr.add(entryField(i, "answer"), "%s answer %q does not fit its %d cells", entryLabel(e), e.Answer, len(e.Cells))
+			continue
+		}
+		for j, cr := range e.Cells {
+			if cr.R < 0 || cr.C < 0 || cr.R >= g.Rows || cr.C >= g.Cols {
+				continue
+			}
+			if g.Cells[cr.R][cr.C].Solution == "" {
+				g.Cells[cr.R][cr.C].Solution = parts[j]
+			}
+		}
+	}
+
+	if verrSol := validateSolutions(g, out.Entries); verrSol != nil {
+		verr.merge(verrSol)
+	}
+	if verr.ok() {
+		return out, nil
+	}
+	return out, verr
+}
+
+// FillAnswersFromCells returns a copy of the puzzle with every entry that has
+// no answer given one built from its cells' solutions, punctuated according
+// to its enumeration. Entries with any unsolved cell are left blank.
+//
+// As with FillCellsFromAnswers, conflicts between existing answers and cell
+// solutions are returned as a ValidationError alongside the filled puzzle.
+func FillAnswersFromCells(p Puzzle) (Puzzle, error) {
+	p.NormalizeGrid()
+	out := p
+	out.Entries = make([]Entry, len(p.Entries))
+	copy(out.Entries, p.Entries)
+	g := p.Grid
+
+	for i, e := range out.Entries {
+		if e.Answer != "" {
+			continue
+		}
+		letters := ""
+		complete := true
+		for _, cr := range e.Cells {
+			if cr.R < 0 || cr.C < 0 || cr.R >= g.Rows || cr.C >= g.Cols || g.Cells[cr.R][cr.C].Solution == "" {
+				complete = false
+				break
+			}
+			letters += NormalizeAnswer(g.Cells[cr.R][cr.C].Solution)
+		}
+		if !complete {
+			continue
+		}
+		if en, err := ParseEnum(e.Enum); err == nil {
+			letters = en.Punctuate(letters)
+		}
+		out.Entries[i].Answer = letters
+	}
+
+	if verr := validateSolutions(g, out.Entries); verr != nil {
+		return out, *verr
+	}
+	return out, nil
+}
+
+func cloneGrid(g Grid) Grid {
+	out := g
+	out.Cells = make([][]Cell, len(g.Cells))
+	for r, row := range g.Cells {
+		out.Cells[r] = make([]Cell, len(row))
+		copy(out.Cells[r], row)
+	}
+	return out
+}
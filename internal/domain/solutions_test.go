@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+// solvedPuzzle is a 3x3 ring:
+//
+//	C A T
+//	O . O
+//	W E T
+func solvedPuzzle() Puzzle {
+	g := makeGrid(3, 3, map[[2]int]bool{
+		{1, 1}: true,
+	})
+	entries := GenerateEntries(g)
+	answers := map[string]string{"1a": "CAT", "1d": "COW", "2d": "TOT", "3a": "WET"}
+	for i, e := range entries {
+		entries[i].Answer = answers[e.ID]
+	}
+	return Puzzle{ID: "p1", Title: "Test", Type: PuzzleQuick, Rows: 3, Cols: 3, Grid: g, Entries: entries}
+}
+
+func TestValidateSolutions_CrossingConflict(t *testing.T) {
+	p := solvedPuzzle()
+	if err := ValidatePuzzle(p); err != nil {
+		t.Fatalf("expected OK, got %v", err)
+	}
+
+	p.Entries[3].Answer = "MET" // 3 across clashes with 1 down at (2,0)
+	err := ValidatePuzzle(p)
+	if err == nil || !strings.Contains(err.Error(), "crossing conflict at (2,0)") {
+		t.Fatalf("expected crossing conflict, got %v", err)
+	}
+
+	p = solvedPuzzle()
+	p.Grid.Cells[0][1].Solution = "E"
+	err = ValidatePuzzle(p)
+	if err == nil || !strings.Contains(err.Error(), "cell solution") {
+		t.Fatalf("expected answer/cell conflict, got %v", err)
+	}
+}
+
+func TestFillCellsFromAnswers(t *testing.T) {
+	p := solvedPuzzle()
+
+	filled, err := FillCellsFromAnswers(p)
+	if err != nil {
+		t.Fatalf("FillCellsFromAnswers: %v", err)
+	}
+	if got := filled.Grid.Cells[2][2].Solution; got != "T" {
+		t.Fatalf("cell (2,2)=%q want T", got)
+	}
+	if p.Grid.Cells[2][2].Solution != "" {
+		t.Fatalf("input puzzle was modified")
+	}
+}
+
+func TestFillAnswersFromCells(t *testing.T) {
+	p := solvedPuzzle()
+	filled, err := FillCellsFromAnswers(p)
+	if err != nil {
+		t.Fatalf("FillCellsFromAnswers: %v", err)
+	}
+	for i := range filled.Entries {
+		filled.Entries[i].Answer = ""
+	}
+	filled.Entries[0].Enum = "1,2"
+
+	out, err := FillAnswersFromCells(filled)
+	if err != nil {
+		t.Fatalf("FillAnswersFromCells: %v", err)
+	}
+	if out.Entries[0].Answer != "C AT" || out.Entries[3].Answer != "WET" {
+		t.Fatalf("unexpected answers: %+v", out.Entries)
+	}
+	if err := ValidatePuzzle(out); err != nil {
+		t.Fatalf("expected derived puzzle to validate, got %v", err)
+	}
+}
+
+func TestFillSolutions_GridDimsFromPuzzle(t *testing.T) {
+	// Puzzle JSON usually leaves the grid's own dimensions out.
+	p := solvedPuzzle()
+	p.Grid.Rows, p.Grid.Cols = 0, 0
+
+	filled, err := FillCellsFromAnswers(p)
+	if err != nil {
+		t.Fatalf("FillCellsFromAnswers: %v", err)
+	}
+	if got := filled.Grid.Cells[2][1].Solution; got != "E" {
+		t.Fatalf("cell (2,1)=%q want E", got)
+	}
+
+	for i := range filled.Entries {
+		filled.Entries[i].Answer = ""
+	}
+	out, _ := FillAnswersFromCells(filled)
+	if out.Entries[0].Answer != "CAT" {
+		t.Fatalf("unexpected answers: %+v", out.Entries)
+	}
+}
@@ -4,8 +4,16 @@ import (
 	"fmt"
 )
 
+// Problem is one validation failure. Field is the JSON path of the value at
+// fault ("entries[3].enum", "grid.cells[2][4].solution"), or empty if the
+// problem isn't about one field.
+type Problem struct {
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
 type ValidationError struct {
-	Problems []string
+	Problems []Problem
 }
 
 func (e ValidationError) Error() string {
@@ -15,13 +23,22 @@ func (e ValidationError) Error() string {
 	// Keep it readable.
 	out := "validation failed:"
 	for _, p := range e.Problems {
-		out += "\n- " + p
+		out += "\n- " + p.Message
 	}
 	return out
 }
 
-func (e *ValidationError) add(format string, args ...any) {
-	e.Problems = append(e.Problems, fmt.Sprintf(format, args...))
+func (e *ValidationError) add(field, format string, args ...any) {
+	e.Problems = append(e.Problems, Problem{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// Add records a problem with the given field.
+func (e *ValidationError) Add(field, message string) {
+	e.Problems = append(e.Problems, Problem{Field: field, Message: message})
+}
+
+func (e *ValidationError) merge(o *ValidationError) {
+	e.Problems = append(e.Problems, o.Problems...)
 }
 
 func (e *ValidationError) ok() bool {
@@ -29,38 +46,57 @@ func (e *ValidationError) ok() bool {
 }
 
 // ValidatePuzzle performs structural validation suitable for both creator and API ingestion.
-// It does not enforce "good crossword" rules (like rotational symmetry), only correctness.
+// It does not enforce "good crossword" rules (like rotational symmetry), only correctness;
+// see LintPuzzle for those.
 func ValidatePuzzle(p Puzzle) error {
+	p.NormalizeGrid()
 	var verr ValidationError
 
 	if p.Rows <= 0 || p.Cols <= 0 {
-		verr.add("puzzle dimensions must be > 0, got %dx%d", p.Rows, p.Cols)
+		verr.add(dimField(p), "puzzle dimensions must be > 0, got %dx%d", p.Rows, p.Cols)
+	}
+
+	if verrMeta := validateMetadata(p); verrMeta != nil {
+		verr.merge(verrMeta)
 	}
 
 	// Grid must match declared dimensions.
 	verrGrid := validateGrid(p.Grid, p.Rows, p.Cols)
 	if verrGrid != nil {
-		for _, msg := range verrGrid.Problems {
-			verr.add("%s", msg)
-		}
+		verr.merge(verrGrid)
 	}
 
 	// Entries can be generated from grid; but if provided, validate them.
 	if len(p.Entries) > 0 {
 		verrEntries := validateEntries(p.Grid, p.Entries)
 		if verrEntries != nil {
-			for _, msg := range verrEntries.Problems {
-				verr.add("%s", msg)
+			verr.merge(verrEntries)
+		}
+
+		// They must also be exactly the set the grid implies. Only
+		// meaningful once the grid itself is sound.
+		if verrGrid == nil {
+			if verrGeom := validateGeometry(p.Grid, p.Entries); verrGeom != nil {
+				verr.merge(verrGeom)
+			}
+
+			// Answers and cell solutions must agree at every cell.
+			if verrSol := validateSolutions(p.Grid, p.Entries); verrSol != nil {
+				verr.merge(verrSol)
 			}
 		}
 	}
 
-	// Clues must map to entries if both are present.
-	if len(p.Entries) > 0 && len(p.Clues) > 0 {
-		verrClues := validateClues(p.Entries, p.Clues)
-		if verrClues != nil {
-			for _, msg := range verrClues.Problems {
-				verr.add("%s", msg)
+	// Clues must map to entries. Without supplied entries they are checked
+	// against the ones the grid implies, which is what gets generated.
+	if len(p.Clues) > 0 {
+		entries := p.Entries
+		if len(entries) == 0 && verrGrid == nil {
+			entries = GenerateEntries(p.Grid)
+		}
+		if len(entries) > 0 {
+			if verrClues := validateClues(p.Grid, entries, p.Clues); verrClues != nil {
+				verr.merge(verrClues)
 			}
 		}
 	}
@@ -75,19 +111,19 @@ func validateGrid(g Grid, rows, cols int) *ValidationError {
 	var verr ValidationError
 
 	if g.Rows != 0 && g.Rows != rows {
-		verr.add("grid.Rows (%d) does not match puzzle rows (%d)", g.Rows, rows)
+		verr.add("grid.rows", "grid.Rows (%d) does not match puzzle rows (%d)", g.Rows, rows)
 	}
 	if g.Cols != 0 && g.Cols != cols {
-		verr.add("grid.Cols (%d) does not match puzzle cols (%d)", g.Cols, cols)
+		verr.add("grid.cols", "grid.Cols (%d) does not match puzzle cols (%d)", g.Cols, cols)
 	}
 
 	if len(g.Cells) != rows {
-		verr.add("grid has %d rows of cells, expected %d", len(g.Cells), rows)
+		verr.add("grid.cells", "grid has %d rows of cells, expected %d", len(g.Cells), rows)
 		return &verr
 	}
 	for r := 0; r < rows; r++ {
 		if len(g.Cells[r]) != cols {
-			verr.add("grid row %d has %d cols, expected %d", r, len(g.Cells[r]), cols)
+			verr.add(fmt.Sprintf("grid.cells[%d]", r), "grid row %d has %d cols, expected %d", r, len(g.Cells[r]), cols)
 			continue
 		}
 		for c := 0; c < cols; c++ {
@@ -95,15 +131,36 @@ func validateGrid(g Grid, rows, cols int) *ValidationError {
 			if cell.R != 0 || cell.C != 0 {
 				// If caller populated R/C, ensure it matches.
 				if cell.R != r || cell.C != c {
-					verr.add("cell coords mismatch at [%d,%d]: has R=%d C=%d", r, c, cell.R, cell.C)
+					verr.add(cellField(r, c, ""), "cell coords mismatch at [%d,%d]: has R=%d C=%d", r, c, cell.R, cell.C)
 				}
 			}
+			if cell.Solution != "" && NormalizeAnswer(cell.Solution) == "" {
+				verr.add(cellField(r, c, "solution"), "cell [%d,%d] solution %q has no letters", r, c, cell.Solution)
+			}
+			// Solvers can't write more than MaxCellValueLen letters, so a
+			// longer rebus could never be solved.
+			if n := NormalizedAnswerLen(cell.Solution); n > MaxCellValueLen {
+				verr.add(cellField(r, c, "solution"), "cell [%d,%d] solution %q is %d letters, max %d", r, c, cell.Solution, n, MaxCellValueLen)
+			}
 			if cell.IsBlock {
-				if cell.Solution != nil {
-					verr.add("block cell [%d,%d] must not have a solution letter", r, c)
+				if cell.Solution != "" {
+					verr.add(cellField(r, c, "solution"), "block cell [%d,%d] must not have a solution letter", r, c)
 				}
 				if cell.IsGiven {
-					verr.add("block cell [%d,%d] must not be marked as given", r, c)
+					verr.add(cellField(r, c, "given"), "block cell [%d,%d] must not be marked as given", r, c)
+				}
+				if cell.BarRight || cell.BarBottom {
+					verr.add(cellField(r, c, ""), "block cell [%d,%d] must not have bars", r, c)
+				}
+				if cell.Annotation != nil {
+					verr.add(cellField(r, c, "annotation"), "block cell [%d,%d] must not be annotated", r, c)
+				}
+			} else {
+				if cell.IsGiven && cell.Solution == "" {
+					verr.add(cellField(r, c, "solution"), "given cell [%d,%d] has no solution letter", r, c)
+				}
+				if cell.Annotation != nil {
+					validateAnnotation(&verr, r, c, *cell.Annotation)
 				}
 			}
 		}
@@ -119,26 +176,43 @@ func validateEntries(g Grid, entries []Entry) *ValidationError {
 	var verr ValidationError
 
 	seen := map[string]bool{}
+	seenIDs := map[string]int{}
 	for i, e := range entries {
 		if e.Dir != Across && e.Dir != Down {
-			verr.add("entry[%d] has invalid direction %q", i, e.Dir)
+			verr.add(entryField(i, "dir"), "entry[%d] has invalid direction %q", i, e.Dir)
+		}
+
+		// IDs link clues to entries, so every entry needs a unique one.
+		if e.ID == "" {
+			verr.add(entryField(i, "id"), "entry[%d] missing id", i)
+		} else {
+			if prev, ok := seenIDs[e.ID]; ok {
+				verr.add(entryField(i, "id"), "entry[%d] duplicates id %q of entry[%d]", i, e.ID, prev)
+			} else {
+				seenIDs[e.ID] = i
+			}
+			if d := idDirection(e.ID); d != "" && d != e.Dir {
+				verr.add(entryField(i, "id"), "entry[%d] id %q does not match its direction %s", i, e.ID, e.Dir)
+			}
 		}
 		if e.Num <= 0 {
-			verr.add("entry[%d] has invalid number %d", i, e.Num)
+			verr.add(entryField(i, "num"), "entry[%d] has invalid number %d", i, e.Num)
 		}
 		if len(e.Cells) == 0 {
-			verr.add("entry[%d] has no cells", i)
+			verr.add(entryField(i, "cells"), "entry[%d] has no cells", i)
 			continue
 		}
 
 		// Validate contiguity and non-block membership.
 		for j, cr := range e.Cells {
 			if cr.R < 0 || cr.C < 0 || cr.R >= g.Rows || cr.C >= g.Cols {
-				verr.add("entry[%d] cell[%d] out of bounds: (%d,%d)", i, j, cr.R, cr.C)
+				verr.add(entryField(i, fmt.Sprintf("cells[%d]", j)), "entry[%d] cell[%d] out of bounds: (%d,%d)", i, j, cr.R, cr.C)
 				continue
 			}
-			if g.Cells[cr.R][cr.C].IsBlock {
-				verr.add("entry[%d] includes block cell (%d,%d)", i, cr.R, cr.C)
+			// The grid may be malformed (validateGrid reports that), so
+			// bound by the cells it actually has.
+			if cr.R < len(g.Cells) && cr.C < len(g.Cells[cr.R]) && g.Cells[cr.R][cr.C].IsBlock {
+				verr.add(entryField(i, fmt.Sprintf("cells[%d]", j)), "entry[%d] includes block cell (%d,%d)", i, cr.R, cr.C)
 			}
 		}
 
@@ -149,25 +223,34 @@ func validateEntries(g Grid, entries []Entry) *ValidationError {
 			switch e.Dir {
 			case Across:
 				if curr.R != prev.R || curr.C != prev.C+1 {
-					verr.add("entry[%d] across cells not contiguous at index %d: (%d,%d)->(%d,%d)",
+					verr.add(entryField(i, fmt.Sprintf("cells[%d]", j)), "entry[%d] across cells not contiguous at index %d: (%d,%d)->(%d,%d)",
 						i, j, prev.R, prev.C, curr.R, curr.C)
 				}
 			case Down:
 				if curr.C != prev.C || curr.R != prev.R+1 {
-					verr.add("entry[%d] down cells not contiguous at index %d: (%d,%d)->(%d,%d)",
+					verr.add(entryField(i, fmt.Sprintf("cells[%d]", j)), "entry[%d] down cells not contiguous at index %d: (%d,%d)->(%d,%d)",
 						i, j, prev.R, prev.C, curr.R, curr.C)
 				}
 			}
+			if g.barAfter(prev.R, prev.C, e.Dir) {
+				verr.add(entryField(i, fmt.Sprintf("cells[%d]", j)), "entry[%d] crosses a bar between (%d,%d) and (%d,%d)",
+					i, prev.R, prev.C, curr.R, curr.C)
+			}
 		}
 
+		// Rebus cells hold several letters, so lengths are compared against
+		// the letter count rather than the cell count.
+		letters := g.LetterCount(e.Cells)
+
 		// Enum validation (if provided).
 		if e.Enum != "" {
 			en, err := ParseEnum(e.Enum)
 			if err != nil {
-				verr.add("entry[%d] enum invalid: %v", i, err)
+				verr.add(entryField(i, "enum"), "entry[%d] enum invalid: %v", i, err)
 			} else {
-				if en.Total != len(e.Cells) {
-					verr.add("entry[%d] enum total %d does not match cell count %d", i, en.Total, len(e.Cells))
+				if en.Total != letters {
+					verr.add(entryField(i, "enum"), "entry[%d] enum total %d does not match letter count %d (%d cells)",
+						i, en.Total, letters, len(e.Cells))
 				}
 			}
 		}
@@ -175,16 +258,22 @@ func validateEntries(g Grid, entries []Entry) *ValidationError {
 		// Answer validation (if provided).
 		if e.Answer != "" {
 			n := NormalizedAnswerLen(e.Answer)
-			if n != len(e.Cells) {
-				verr.add("entry[%d] answer length %d does not match cell count %d (answer=%q)",
-					i, n, len(e.Cells), e.Answer)
+			if n != letters {
+				verr.add(entryField(i, "answer"), "entry[%d] answer length %d does not match letter count %d (%d cells, answer=%q)",
+					i, n, letters, len(e.Cells), e.Answer)
+			} else if e.Enum != "" {
+				if en, err := ParseEnum(e.Enum); err == nil {
+					if err := en.MatchAnswer(e.Answer); err != nil {
+						verr.add(entryField(i, "answer"), "entry[%d] answer punctuation does not match enum: %v", i, err)
+					}
+				}
 			}
 		}
 
 		// Uniqueness: number+dir should be unique.
 		key := fmt.Sprintf("%s:%d", e.Dir, e.Num)
 		if seen[key] {
-			verr.add("duplicate entry number for %s %d", e.Dir, e.Num)
+			verr.add(entryField(i, "num"), "duplicate entry number for %s %d", e.Dir, e.Num)
 		}
 		seen[key] = true
 	}
@@ -200,7 +289,7 @@ func validateEntries(g Grid, entries []Entry) *ValidationError {
 		for _, cr := range e.Cells {
 			ok := ownerKey{r: cr.R, c: cr.C, dir: e.Dir}
 			if prev, exists := owners[ok]; exists {
-				verr.add("cell (%d,%d) belongs to multiple %s entries (%s and entry[%d])", cr.R, cr.C, e.Dir, prev, i)
+				verr.add(entryField(i, "cells"), "cell (%d,%d) belongs to multiple %s entries (%s and entry[%d])", cr.R, cr.C, e.Dir, prev, i)
 			} else {
 				owners[ok] = fmt.Sprintf("entry[%d]", i)
 			}
@@ -213,28 +302,65 @@ func validateEntries(g Grid, entries []Entry) *ValidationError {
 	return &verr
 }
 
-func validateClues(entries []Entry, clues []Clue) *ValidationError {
+func validateClues(g Grid, entries []Entry, clues []Clue) *ValidationError {
 	var verr ValidationError
 
-	entryIDs := map[string]bool{}
-	// If IDs are empty in early MVP, map by (dir,num) is alternative;
-	// but here we assume creator flow will set Entry.ID.
-	for _, e := range entries {
+	// validateEntries guarantees every entry has a unique ID.
+	entryIDs := map[string]int{}
+	for i, e := range entries {
 		if e.ID != "" {
-			entryIDs[e.ID] = true
+			entryIDs[e.ID] = i
 		}
 	}
 
+	// Each entry may be answered by at most one clue, whether as a clue's
+	// primary entry or as a linked part.
+	claimedBy := map[string]int{}
+
 	for i, c := range clues {
 		if c.EntryID == "" {
-			verr.add("clue[%d] missing entryId", i)
+			verr.add(clueField(i, "entryId"), "clue[%d] missing entryId", i)
 			continue
 		}
-		if len(entryIDs) > 0 && !entryIDs[c.EntryID] {
-			verr.add("clue[%d] references unknown entryId %q", i, c.EntryID)
-		}
 		if stringsTrim(c.Text) == "" {
-			verr.add("clue[%d] has empty text", i)
+			verr.add(clueField(i, "text"), "clue[%d] has empty text", i)
+		}
+
+		letters := 0
+		resolved := true
+		for j, id := range c.EntryIDs() {
+			if id == "" {
+				verr.add(clueEntryField(i, j), "clue[%d] linked entry %d has empty id", i, j)
+				resolved = false
+				continue
+			}
+			if prev, ok := claimedBy[id]; ok {
+				if prev == i {
+					verr.add(clueEntryField(i, j), "clue[%d] lists entry %q more than once", i, id)
+				} else {
+					verr.add(clueEntryField(i, j), "entry %q is covered by both clue[%d] and clue[%d]", id, prev, i)
+				}
+			}
+			claimedBy[id] = i
+
+			idx, ok := entryIDs[id]
+			if !ok {
+				verr.add(clueEntryField(i, j), "clue[%d] references unknown entryId %q", i, id)
+				resolved = false
+				continue
+			}
+			letters += g.LetterCount(entries[idx].Cells)
+		}
+
+		// Combined enumeration (linked clues).
+		if c.Enum != "" {
+			en, err := ParseEnum(c.Enum)
+			if err != nil {
+				verr.add(clueField(i, "enum"), "clue[%d] enum invalid: %v", i, err)
+			} else if resolved && en.Total != letters {
+				verr.add(clueField(i, "enum"), "clue[%d] enum total %d does not match letter count %d across %d entries",
+					i, en.Total, letters, len(c.EntryIDs()))
+			}
 		}
 	}
 
@@ -244,6 +370,51 @@ func validateClues(entries []Entry, clues []Clue) *ValidationError {
 	return &verr
 }
 
+// Field paths for problems, following the puzzle's JSON names.
+
+func dimField(p Puzzle) string {
+	if p.Rows <= 0 {
+		return "rows"
+	}
+	return "cols"
+}
+
+func entryField(i int, name string) string {
+	f := fmt.Sprintf("entries[%d]", i)
+	if name != "" {
+		f += "." + name
+	}
+	return f
+}
+
+func clueField(i int, name string) string {
+	return fmt.Sprintf("clues[%d].%s", i, name)
+}
+
+// clueEntryField names the j'th of a clue's EntryIDs: its entryId, then
+// its linkedEntryIds.
+func clueEntryField(i, j int) string {
+	if j == 0 {
+		return clueField(i, "entryId")
+	}
+	return fmt.Sprintf("clues[%d].linkedEntryIds[%d]", i, j-1)
+}
+
+func cellField(r, c int, name string) string {
+	f := fmt.Sprintf("grid.cells[%d][%d]", r, c)
+	if name != "" {
+		f += "." + name
+	}
+	return f
+}
+
+func annotationField(r, c int, name string) string {
+	if name == "" {
+		return cellField(r, c, "annotation")
+	}
+	return cellField(r, c, "annotation."+name)
+}
+
 func stringsTrim(s string) string {
 	// tiny helper to avoid importing strings twice at top-level
 	i := 0
@@ -1,6 +1,11 @@
 package domain
 
-import "testing"
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
 
 func makeGrid(rows, cols int, blocks map[[2]int]bool) Grid {
 	cells := make([][]Cell, rows)
@@ -45,6 +50,17 @@ func TestValidatePuzzle_BadGridShape(t *testing.T) {
 	if err := ValidatePuzzle(p); err == nil {
 		t.Fatalf("expected error, got nil")
 	}
+
+	// Entries over a missing or ragged grid are reported, not indexed into.
+	entries := []Entry{{ID: "1a", Dir: Across, Num: 1, Cells: []CellRef{{2, 3}, {2, 4}}}}
+	for _, p := range []Puzzle{
+		{ID: "p1", Title: "Test", Rows: 5, Cols: 5, Entries: entries},
+		{ID: "p1", Title: "Test", Rows: 5, Cols: 5, Grid: g, Entries: entries},
+	} {
+		if err := ValidatePuzzle(p); err == nil {
+			t.Fatalf("expected error, got nil")
+		}
+	}
 }
 
 func TestValidateEntries_EnumAndAnswerMatch(t *testing.T) {
@@ -75,20 +91,142 @@ func TestValidateEntries_OK_ContiguousAcross(t *testing.T) {
 		{0, 1}: true,
 	})
 
+	// Supplied entries must be the full set the grid implies.
+	entries := GenerateEntries(g)
+	for i, e := range entries {
+		if e.Dir == Across && e.Cells[0] == (CellRef{0, 2}) {
+			entries[i].Enum = "3"
+			entries[i].Answer = "CAT"
+		}
+	}
+
+	p := Puzzle{ID: "p1", Title: "Test", Type: PuzzleQuick, Rows: 5, Cols: 5, Grid: g, Entries: entries}
+
+	if err := ValidatePuzzle(p); err != nil {
+		t.Fatalf("expected OK, got %v", err)
+	}
+}
+
+func TestValidateEntries_RebusCell(t *testing.T) {
+	g := makeGrid(1, 3, nil)
+	g.Cells[0][1].Solution = "HEART"
+
 	entries := []Entry{
 		{
 			ID:     "e1",
 			Dir:    Across,
 			Num:    1,
-			Cells:  []CellRef{{0, 2}, {0, 3}, {0, 4}},
-			Enum:   "3",
-			Answer: "CAT",
+			Cells:  []CellRef{{0, 0}, {0, 1}, {0, 2}},
+			Enum:   "7",
+			Answer: "SHEARTS",
 		},
 	}
+	p := Puzzle{ID: "p1", Title: "Test", Type: PuzzleQuick, Rows: 1, Cols: 3, Grid: g, Entries: entries}
 
-	p := Puzzle{ID: "p1", Title: "Test", Type: PuzzleQuick, Rows: 5, Cols: 5, Grid: g, Entries: entries}
+	if err := ValidatePuzzle(p); err != nil {
+		t.Fatalf("expected OK, got %v", err)
+	}
+
+	parts, ok := g.SplitAnswer(entries[0].Cells, "s-hearts")
+	if !ok || len(parts) != 3 || parts[0] != "S" || parts[1] != "HEART" || parts[2] != "S" {
+		t.Fatalf("SplitAnswer=%q ok=%v", parts, ok)
+	}
+
+	p.Entries[0].Answer = "SHE"
+	if err := ValidatePuzzle(p); err == nil {
+		t.Fatalf("expected answer length error, got nil")
+	}
+
+	// A solver can't enter more than MaxCellValueLen letters in a cell.
+	g.Cells[0][1].Solution = "HEARTBEAT"
+	p.Entries[0].Enum, p.Entries[0].Answer = "11", "SHEARTBEATS"
+	var verr ValidationError
+	if err := ValidatePuzzle(p); !errors.As(err, &verr) || len(verr.Problems) != 1 || verr.Problems[0].Field != "grid.cells[0][1].solution" {
+		t.Fatalf("expected rebus length error, got %v", err)
+	}
+}
 
+func TestValidateGrid_Annotations(t *testing.T) {
+	g := makeGrid(3, 3, map[[2]int]bool{
+		{1, 1}: true,
+	})
+	g.Cells[0][0].Annotation = &CellAnnotation{Shape: ShapeCircle}
+	g.Cells[0][1].Annotation = &CellAnnotation{Fill: "#ffcc00", Label: "A"}
+
+	p := Puzzle{ID: "p1", Title: "Test", Type: PuzzleQuick, Rows: 3, Cols: 3, Grid: g}
 	if err := ValidatePuzzle(p); err != nil {
 		t.Fatalf("expected OK, got %v", err)
 	}
+
+	pub := ToPublic(p)
+	if a := pub.Grid.Cells[0][1].Annotation; a == nil || a.Fill != "#ffcc00" || a.Label != "A" {
+		t.Fatalf("annotation not passed through: %+v", a)
+	}
+
+	g.Cells[1][1].Annotation = &CellAnnotation{Shape: ShapeCircle}
+	if err := ValidatePuzzle(p); err == nil {
+		t.Fatalf("expected error for annotated block, got nil")
+	}
+
+	g.Cells[1][1].Annotation = nil
+	g.Cells[0][0].Annotation = &CellAnnotation{Fill: "yellow"}
+	if err := ValidatePuzzle(p); err == nil {
+		t.Fatalf("expected error for bad fill, got nil")
+	}
+}
+
+func TestValidatePuzzle_Metadata(t *testing.T) {
+	base := Puzzle{
+		ID: "p1", Title: "Test", Type: PuzzleCryptic, Rows: 3, Cols: 3, Grid: makeGrid(3, 3, nil),
+		Author:      "Azed",
+		PublishedOn: NewDate(2024, time.May, 1),
+		Copyright:   "(c) 2024",
+		Difficulty:  "hard",
+		Preamble:    "Eight answers are <i>themed</i>.<br>Solvers should highlight them.",
+	}
+	if err := ValidatePuzzle(base); err != nil {
+		t.Fatalf("expected OK, got %v", err)
+	}
+	if pub := ToPublic(base); pub.PublishedOn != "2024-05-01" || pub.Author != "Azed" {
+		t.Fatalf("metadata not published: %+v", pub)
+	}
+
+	tests := map[string]func(*Puzzle){
+		"long author":     func(p *Puzzle) { p.Author = strings.Repeat("x", 101) },
+		"markup in title": func(p *Puzzle) { p.Title = "<b>Bold</b>" },
+		"script preamble": func(p *Puzzle) { p.Preamble = "<script>alert(1)</script>" },
+		"attributes":      func(p *Puzzle) { p.Notes = `<i class="x">hi</i>` },
+		"stray bracket":   func(p *Puzzle) { p.Notes = "a > b" },
+	}
+	for name, mutate := range tests {
+		p := base
+		mutate(&p)
+		if err := ValidatePuzzle(p); err == nil {
+			t.Fatalf("%s: expected error, got nil", name)
+		}
+	}
+}
+
+func TestValidatePuzzle_ProblemFields(t *testing.T) {
+	p := solvedPuzzle()
+	p.Title = "<b>Test</b>"
+	p.Entries[2].Enum = "3-"
+	p.Grid.Cells[1][1].IsGiven = true
+	p.Clues = []Clue{{EntryID: "1a", LinkedEntryIDs: []string{"9d"}, Text: "Clue"}}
+
+	err := ValidatePuzzle(p)
+	var verr ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+
+	fields := map[string]bool{}
+	for _, pr := range verr.Problems {
+		fields[pr.Field] = true
+	}
+	for _, want := range []string{"title", "entries[2].enum", "grid.cells[1][1].given", "clues[0].linkedEntryIds[0]"} {
+		if !fields[want] {
+			t.Fatalf("no problem for %s in %+v", want, verr.Problems)
+		}
+	}
 }
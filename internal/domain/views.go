@@ -9,6 +9,15 @@ type PuzzlePublic struct {
 	Grid    GridPublic    `json:"grid"`
 	Entries []EntryPublic `json:"entries"`
 	Clues   []CluePublic  `json:"clues"`
+
+	Revision int `json:"revision"`
+
+	Author      string `json:"author,omitempty"`
+	PublishedOn string `json:"publishedOn,omitempty"` // YYYY-MM-DD
+	Copyright   string `json:"copyright,omitempty"`
+	Difficulty  string `json:"difficulty,omitempty"`
+	Preamble    string `json:"preamble,omitempty"`
+	Notes       string `json:"notes,omitempty"`
 }
 
 type GridPublic struct {
@@ -22,19 +31,59 @@ type CellPublic struct {
 	C     int  `json:"c"`
 	Block bool `json:"block"`
 	Given bool `json:"given"`
+	// Letter is the pre-filled solution of a given cell; empty otherwise.
+	Letter    string `json:"letter,omitempty"`
+	BarRight  bool   `json:"barRight,omitempty"`
+	BarBottom bool   `json:"barBottom,omitempty"`
+
+	Annotation *AnnotationPublic `json:"annotation,omitempty"`
+}
+
+type AnnotationPublic struct {
+	Shape AnnotationShape `json:"shape,omitempty"`
+	Fill  string          `json:"fill,omitempty"`
+	Label string          `json:"label,omitempty"`
 }
 
 type EntryPublic struct {
-	ID    string    `json:"id"`
-	Dir   Direction `json:"dir"`
-	Num   int       `json:"num"`
-	Cells []CellRef `json:"cells"`
-	Enum  string    `json:"enum"`
+	ID        string     `json:"id"`
+	Dir       Direction  `json:"dir"`
+	Num       int        `json:"num"`
+	Cells     []CellRef  `json:"cells"`
+	Enum      string     `json:"enum"`
+	EnumParts []EnumPart `json:"enumParts,omitempty"`
+}
+
+// EnumPart is one word of an enumeration. Sep is the separator that follows
+// it, empty for the last part, so clients can draw word breaks and hyphens.
+type EnumPart struct {
+	Len int     `json:"len"`
+	Sep EnumSep `json:"sep,omitempty"`
 }
 
 type CluePublic struct {
-	EntryID     string   `json:"entryId"`
+	EntryID string `json:"entryId"`
+	// EntryIDs lists every entry of a linked clue, primary entry first.
+	EntryIDs  []string   `json:"entryIds,omitempty"`
+	Enum      string     `json:"enum,omitempty"`
+	EnumParts []EnumPart `json:"enumParts,omitempty"`
+	// SeeEntryID marks a generated "See N" stub, pointing at the entry
+	// whose clue covers this one.
+	SeeEntryID  string   `json:"seeEntryId,omitempty"`
 	Text        string   `json:"text"`
 	Explanation *string  `json:"explanation,omitempty"`
 	Tags        []string `json:"tags,omitempty"`
 }
+
+// PuzzleSummary is the lightweight listing view of a puzzle.
+type PuzzleSummary struct {
+	ID          string     `json:"id"`
+	Revision    int        `json:"revision"`
+	Title       string     `json:"title"`
+	Type        PuzzleType `json:"type"`
+	Rows        int        `json:"rows"`
+	Cols        int        `json:"cols"`
+	Author      string     `json:"author,omitempty"`
+	PublishedOn string     `json:"publishedOn,omitempty"` // YYYY-MM-DD
+	Difficulty  string     `json:"difficulty,omitempty"`
+}
@@ -0,0 +1,212 @@
+// Package library seeds the puzzle store from a directory of puzzle JSON
+// files and keeps it in step as files are added or changed.
+package library
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/danny-molnar/crossword/internal/domain"
+	"github.com/danny-molnar/crossword/internal/store"
+)
+
+// Library loads the *.json files in a directory into a PuzzleStore. Each
+// file holds one puzzle; its ID defaults to the file name (puz_demo.json is
+// puz_demo). A changed file is stored as a new revision of its puzzle, so
+// sessions on the old one carry on until migrated. Removing a file leaves
+// its puzzle in the store.
+//
+// The library only writes puzzles it stored itself. A file whose ID was
+// created through the creator API fails to load, and once the API stores a
+// revision of a library puzzle, the API owns it: later changes to its file
+// fail rather than stack a revision on top of the API's.
+type Library struct {
+	dir     string
+	puzzles *store.PuzzleStore
+
+	mu    sync.Mutex
+	files map[string]file // name -> last seen state
+}
+
+type file struct {
+	sum [sha256.Size]byte
+	id  string // puzzle stored from it; empty if it failed
+	rev int    // revision of id stored from it
+}
+
+// Report is the outcome of one Load.
+type Report struct {
+	Loaded []Loaded
+	Failed []Failure
+}
+
+// Loaded is a file stored as a puzzle revision.
+type Loaded struct {
+	File     string
+	PuzzleID string
+	Revision int
+}
+
+// Failure is a file that couldn't be stored. Problems lists the validation
+// failures, if that is why.
+type Failure struct {
+	File     string
+	Err      error
+	Problems []domain.Problem
+}
+
+// Empty reports whether the Load found nothing new.
+func (r Report) Empty() bool { return len(r.Loaded) == 0 && len(r.Failed) == 0 }
+
+func New(dir string, puzzles *store.PuzzleStore) *Library {
+	return &Library{dir: dir, puzzles: puzzles, files: map[string]file{}}
+}
+
+// Load reads every file and stores the valid ones that are new or whose
+// contents have changed since the last Load. Files are compared by hash,
+// as a modification time can stay the same across a quick rewrite. A file
+// that fails keeps whatever revision an earlier version of it stored. The
+// error is for the directory itself.
+func (l *Library) Load() (Report, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	dirents, err := os.ReadDir(l.dir)
+	if err != nil {
+		return Report{}, fmt.Errorf("read puzzle library: %w", err)
+	}
+
+	var rep Report
+	present := map[string]bool{}
+	for _, de := range dirents {
+		name := de.Name()
+		if de.IsDir() || filepath.Ext(name) != ".json" || strings.HasPrefix(name, ".") {
+			continue
+		}
+		present[name] = true
+
+		data, err := os.ReadFile(filepath.Join(l.dir, name))
+		if err != nil {
+			rep.Failed = append(rep.Failed, Failure{File: name, Err: err})
+			continue
+		}
+		prev, seen := l.files[name]
+		cur := file{sum: sha256.Sum256(data), id: prev.id, rev: prev.rev}
+		if seen && prev.sum == cur.sum {
+			continue
+		}
+
+		loaded, err := l.load(name, data, prev)
+		if err != nil {
+			f := Failure{File: name, Err: err}
+			var verr domain.ValidationError
+			if errors.As(err, &verr) {
+				f.Problems = verr.Problems
+			}
+			rep.Failed = append(rep.Failed, f)
+		} else {
+			rep.Loaded = append(rep.Loaded, loaded)
+			cur.id, cur.rev = loaded.PuzzleID, loaded.Revision
+		}
+		l.files[name] = cur
+	}
+
+	// Forget removed files, so they load again if put back.
+	for name := range l.files {
+		if !present[name] {
+			delete(l.files, name)
+		}
+	}
+	return rep, nil
+}
+
+// load decodes, completes and validates one file's puzzle and stores it.
+// prev is what the file last stored, if anything.
+func (l *Library) load(name string, data []byte, prev file) (Loaded, error) {
+	var p domain.Puzzle
+	if err := json.Unmarshal(data, &p); err != nil {
+		return Loaded{}, fmt.Errorf("invalid json: %w", err)
+	}
+
+	if p.ID == "" {
+		p.ID = strings.TrimSuffix(name, ".json")
+	}
+	for other, f := range l.files {
+		if other != name && f.id == p.ID {
+			return Loaded{}, fmt.Errorf("puzzle id %q is already loaded from %s", p.ID, other)
+		}
+	}
+
+	p.NormalizeGrid()
+
+	// Revisions are assigned by the store.
+	p.Revision = 0
+
+	// Files may leave the entries out. Supplied ones are validated as they
+	// are, so mistakes in them are reported rather than regenerated away;
+	// generation needs a well-formed grid, and otherwise validation reports
+	// the grid's problems.
+	if len(p.Entries) == 0 {
+		if err := domain.ValidatePuzzle(domain.Puzzle{Rows: p.Rows, Cols: p.Cols, Grid: p.Grid}); err == nil {
+			p.Entries = domain.GenerateEntries(p.Grid)
+		}
+	}
+
+	if err := domain.ValidatePuzzle(p); err != nil {
+		return Loaded{}, err
+	}
+
+	// Store on top of the revision this file stored last, or as a new
+	// puzzle; anything else means the puzzle isn't the library's (see
+	// Library).
+	want := 0
+	if prev.id == p.ID {
+		want = prev.rev
+	}
+	stored, err := l.puzzles.PutPuzzleIfRevision(p, want)
+	switch {
+	case errors.Is(err, store.ErrVersionMismatch) && want == 0:
+		return Loaded{}, fmt.Errorf("puzzle id %q is already in use by a puzzle the library didn't load", p.ID)
+	case errors.Is(err, store.ErrVersionMismatch):
+		return Loaded{}, fmt.Errorf("puzzle %q has been revised through the API (now revision %d); its file no longer updates it", p.ID, stored.Revision)
+	case err != nil:
+		return Loaded{}, err
+	}
+	return Loaded{File: name, PuzzleID: stored.ID, Revision: stored.Revision}, nil
+}
+
+// Watch calls Load every interval until ctx is done, passing each report
+// with something in it to notify, along with any error that differs from
+// the last one. It doesn't do the first Load.
+func (l *Library) Watch(ctx context.Context, interval time.Duration, notify func(Report, error)) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	var lastErr string
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			rep, err := l.Load()
+			if err != nil {
+				if err.Error() != lastErr {
+					lastErr = err.Error()
+					notify(rep, err)
+				}
+				continue
+			}
+			lastErr = ""
+			if !rep.Empty() {
+				notify(rep, nil)
+			}
+		}
+	}
+}
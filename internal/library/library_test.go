@@ -0,0 +1,170 @@
+package library
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danny-molnar/crossword/internal/store"
+)
+
+const demo = `{
+  "title": "Demo", "type": "quick", "rows": 3, "cols": 3,
+  "grid": {"cells": [
+    [{"r":0,"c":0,"solution":"C"},{"r":0,"c":1,"solution":"A"},{"r":0,"c":2,"solution":"T"}],
+    [{"r":1,"c":0,"solution":"A"},{"r":1,"c":1,"block":true},{"r":1,"c":2,"solution":"O"}],
+    [{"r":2,"c":0,"solution":"B"},{"r":2,"c":1,"solution":"E"},{"r":2,"c":2,"solution":"E"}]
+  ]},
+  "clues": [
+    {"entryId":"1a","text":"Pet"}, {"entryId":"1d","text":"Taxi"},
+    {"entryId":"2d","text":"Foot digit"}, {"entryId":"3a","text":"Buzzer"}
+  ]
+}`
+
+// write puts a file in dir, always with the same modification time, as a
+// quick rewrite on a coarse-grained filesystem would have. Changes must be
+// seen from the contents alone.
+func write(t *testing.T, dir, name, data string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	mod := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := os.Chtimes(path, mod, mod); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLibrary_Load(t *testing.T) {
+	dir := t.TempDir()
+	ps := store.NewPuzzleStore()
+	lib := New(dir, ps)
+
+	write(t, dir, "puz_demo.json", demo)
+	write(t, dir, "broken.json", strings.Replace(demo, `"entryId":"1a"`, `"entryId":"9a"`, 1))
+	write(t, dir, "notes.txt", "not a puzzle")
+
+	rep, err := lib.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rep.Loaded) != 1 || rep.Loaded[0].PuzzleID != "puz_demo" || rep.Loaded[0].Revision != 1 {
+		t.Fatalf("loaded=%+v", rep.Loaded)
+	}
+	if len(rep.Failed) != 1 || rep.Failed[0].File != "broken.json" || len(rep.Failed[0].Problems) == 0 {
+		t.Fatalf("failed=%+v", rep.Failed)
+	}
+	if !strings.HasPrefix(rep.Failed[0].Problems[0].Field, "clues[0]") {
+		t.Fatalf("problems=%+v", rep.Failed[0].Problems)
+	}
+
+	p, err := ps.GetPuzzle("puz_demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Entries) != 4 {
+		t.Fatalf("entries not generated: %+v", p.Entries)
+	}
+
+	// Nothing changed.
+	if rep, _ := lib.Load(); !rep.Empty() {
+		t.Fatalf("second load=%+v", rep)
+	}
+
+	// Touched but identical: no new revision.
+	write(t, dir, "puz_demo.json", demo)
+	if rep, _ := lib.Load(); !rep.Empty() {
+		t.Fatalf("load after touch=%+v", rep)
+	}
+
+	// Edited, fixed and added files are picked up, even an edit that keeps
+	// the size.
+	write(t, dir, "puz_demo.json", strings.Replace(demo, `"Pet"`, `"Cat"`, 1))
+	write(t, dir, "broken.json", strings.Replace(demo, `"title": "Demo"`, `"id": "puz_two", "title": "Two"`, 1))
+	write(t, dir, "dup.json", strings.Replace(demo, `"title": "Demo"`, `"id": "puz_demo", "title": "Dup"`, 1))
+	rep, err = lib.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rep.Loaded) != 2 {
+		t.Fatalf("loaded after edits=%+v", rep.Loaded)
+	}
+	if len(rep.Failed) != 1 || rep.Failed[0].File != "dup.json" {
+		t.Fatalf("failed after edits=%+v", rep.Failed)
+	}
+	if p, _ := ps.GetPuzzle("puz_demo"); p.Revision != 2 || p.Clues[0].Text != "Cat" {
+		t.Fatalf("puz_demo after edit: revision %d, clue %q", p.Revision, p.Clues[0].Text)
+	}
+	if _, err := ps.GetPuzzle("puz_two"); err != nil {
+		t.Fatalf("fixed file not loaded: %v", err)
+	}
+}
+
+func TestLibrary_MalformedGrid(t *testing.T) {
+	dir := t.TempDir()
+	lib := New(dir, store.NewPuzzleStore())
+
+	// Dimensions and entries but no cells, and a ragged row.
+	write(t, dir, "nocells.json", `{"rows":3,"cols":3,"entries":[{"id":"1a","dir":"across","num":1,"cells":[{"r":0,"c":0}]}]}`)
+	write(t, dir, "ragged.json", strings.Replace(demo, `,{"r":1,"c":2,"solution":"O"}`, ``, 1))
+
+	rep, err := lib.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rep.Loaded) != 0 || len(rep.Failed) != 2 {
+		t.Fatalf("load=%+v", rep)
+	}
+	for _, f := range rep.Failed {
+		if len(f.Problems) == 0 {
+			t.Fatalf("%s failed without problems: %v", f.File, f.Err)
+		}
+	}
+}
+
+func TestLibrary_MissingDir(t *testing.T) {
+	lib := New(filepath.Join(t.TempDir(), "nope"), store.NewPuzzleStore())
+	if _, err := lib.Load(); err == nil {
+		t.Fatalf("expected an error for a missing directory")
+	}
+}
+
+func TestLibrary_APIOwnedPuzzles(t *testing.T) {
+	dir := t.TempDir()
+	ps := store.NewPuzzleStore()
+	lib := New(dir, ps)
+
+	write(t, dir, "puz_demo.json", demo)
+	if rep, err := lib.Load(); err != nil || len(rep.Loaded) != 1 {
+		t.Fatalf("first load=%+v, %v", rep, err)
+	}
+
+	// A puzzle created through the API isn't overwritten by a file.
+	api, _ := ps.GetPuzzle("puz_demo")
+	api.ID = "puz_api"
+	ps.PutPuzzle(api)
+	write(t, dir, "puz_api.json", demo)
+
+	// Once the API revises a library puzzle, its file no longer does.
+	api.ID = "puz_demo"
+	api.Title = "Edited online"
+	ps.PutPuzzle(api)
+	write(t, dir, "puz_demo.json", strings.Replace(demo, `"Pet"`, `"Cat"`, 1))
+
+	rep, err := lib.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rep.Loaded) != 0 || len(rep.Failed) != 2 {
+		t.Fatalf("load=%+v", rep)
+	}
+	if p, _ := ps.GetPuzzle("puz_api"); p.Revision != 1 {
+		t.Fatalf("puz_api revision %d, want 1", p.Revision)
+	}
+	if p, _ := ps.GetPuzzle("puz_demo"); p.Revision != 2 || p.Title != "Edited online" {
+		t.Fatalf("puz_demo revision %d %q, want the API's revision 2", p.Revision, p.Title)
+	}
+}
@@ -0,0 +1,248 @@
+// Package openapi builds an OpenAPI 3 document from route descriptions and
+// the Go types routes read and write. Schemas are derived from the types by
+// reflection, following encoding/json's rules, so they can't drift from what
+// the handlers actually send.
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Operation describes one route. Request and Response are zero values of
+// the types read from and written to the body (nil for none).
+type Operation struct {
+	Summary     string
+	Description string
+	Tags        []string
+
+	Query []Param
+	// IfMatch marks writes that honour If-Match against the resource's
+	// ETag; ETag marks responses that carry one.
+	IfMatch bool
+	ETag    bool
+	// Creator marks routes that need the creator bearer token.
+	Creator bool
+
+	Request any
+
+	// Status is the success status; 200 if zero.
+	Status   int
+	Response any
+	// ContentType of the success response; "application/json" if empty.
+	ContentType string
+
+	// Errors lists, by status, error responses whose body isn't the usual
+	// error body (see NewSpec). Other errors are described as "default".
+	Errors map[int]any
+}
+
+// Param is a query parameter.
+type Param struct {
+	Name        string
+	Description string
+	Type        string // JSON schema type; "string" if empty
+	Required    bool
+}
+
+// Route is a registered route: an HTTP method and a chi pattern.
+type Route struct {
+	Method  string
+	Pattern string
+}
+
+func (r Route) String() string { return r.Method + " " + r.Pattern }
+
+// Document is an OpenAPI 3 document.
+type Document struct {
+	OpenAPI    string                           `json:"openapi"`
+	Info       Info                             `json:"info"`
+	Paths      map[string]map[string]*operation `json:"paths"`
+	Components components                       `json:"components"`
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]securityScheme `json:"securitySchemes,omitempty"`
+}
+
+type securityScheme struct {
+	Type   string `json:"type"`
+	Scheme string `json:"scheme"`
+}
+
+type operation struct {
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []parameter           `json:"parameters,omitempty"`
+	RequestBody *requestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]response   `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+type parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type requestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]mediaType `json:"content"`
+}
+
+type response struct {
+	Description string               `json:"description"`
+	Headers     map[string]header    `json:"headers,omitempty"`
+	Content     map[string]mediaType `json:"content,omitempty"`
+}
+
+type header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type mediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Spec collects operations into a Document.
+type Spec struct {
+	info  Info
+	ops   map[string]Operation
+	err   any // error body, sent with non-2xx responses not in Operation.Errors
+	types *schemas
+}
+
+// NewSpec starts a document. ops are keyed by Route.String(); errorBody is
+// the type of every error response an Operation doesn't list in Errors.
+func NewSpec(info Info, ops map[string]Operation, errorBody any) *Spec {
+	return &Spec{info: info, ops: ops, err: errorBody, types: newSchemas()}
+}
+
+var pathParam = regexp.MustCompile(`\{([^}:]+)(:[^}]*)?\}`)
+
+// Build describes the given routes. It returns the routes that have no
+// Operation, and the Operations that match no route, alongside the
+// document.
+func (s *Spec) Build(routes []Route) (doc Document, undocumented []Route, unrouted []string) {
+	doc = Document{
+		OpenAPI: "3.0.3",
+		Info:    s.info,
+		Paths:   map[string]map[string]*operation{},
+		Components: components{
+			SecuritySchemes: map[string]securityScheme{
+				"creatorKey": {Type: "http", Scheme: "bearer"},
+			},
+		},
+	}
+
+	seen := map[string]bool{}
+	for _, rt := range routes {
+		op, ok := s.ops[rt.String()]
+		if !ok {
+			undocumented = append(undocumented, rt)
+			continue
+		}
+		seen[rt.String()] = true
+
+		path := pathParam.ReplaceAllString(rt.Pattern, "{$1}")
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = map[string]*operation{}
+		}
+		doc.Paths[path][strings.ToLower(rt.Method)] = s.operation(rt, op)
+	}
+
+	for key := range s.ops {
+		if !seen[key] {
+			unrouted = append(unrouted, key)
+		}
+	}
+	sort.Strings(unrouted)
+
+	doc.Components.Schemas = s.types.defs
+	return doc, undocumented, unrouted
+}
+
+func (s *Spec) operation(rt Route, op Operation) *operation {
+	out := &operation{
+		Summary:     op.Summary,
+		Description: op.Description,
+		Tags:        op.Tags,
+		Responses:   map[string]response{},
+	}
+
+	for _, m := range pathParam.FindAllStringSubmatch(rt.Pattern, -1) {
+		out.Parameters = append(out.Parameters, parameter{
+			Name: m[1], In: "path", Required: true, Schema: &Schema{Type: "string"},
+		})
+	}
+	for _, q := range op.Query {
+		typ := q.Type
+		if typ == "" {
+			typ = "string"
+		}
+		out.Parameters = append(out.Parameters, parameter{
+			Name: q.Name, In: "query", Description: q.Description, Required: q.Required, Schema: &Schema{Type: typ},
+		})
+	}
+	if op.IfMatch {
+		out.Parameters = append(out.Parameters, parameter{
+			Name: "If-Match", In: "header",
+			Description: "Only apply the write if the resource's ETag still matches; 412 with the current state otherwise.",
+			Schema:      &Schema{Type: "string"},
+		})
+	}
+	if op.Creator {
+		out.Security = []map[string][]string{{"creatorKey": {}}}
+	}
+
+	if op.Request != nil {
+		out.RequestBody = &requestBody{
+			Required: true,
+			Content:  map[string]mediaType{"application/json": {Schema: s.types.of(op.Request)}},
+		}
+	}
+
+	status := op.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	ok := response{Description: http.StatusText(status)}
+	if op.Response != nil {
+		ct := op.ContentType
+		if ct == "" {
+			ct = "application/json"
+		}
+		ok.Content = map[string]mediaType{ct: {Schema: s.types.of(op.Response)}}
+	}
+	if op.ETag {
+		ok.Headers = map[string]header{
+			"ETag": {Description: "Version of the returned resource, for If-Match.", Schema: &Schema{Type: "string"}},
+		}
+	}
+	out.Responses[fmt.Sprint(status)] = ok
+
+	for status, body := range op.Errors {
+		out.Responses[fmt.Sprint(status)] = response{
+			Description: http.StatusText(status),
+			Content:     map[string]mediaType{"application/json": {Schema: s.types.of(body)}},
+		}
+	}
+	out.Responses["default"] = response{
+		Description: "Error",
+		Content:     map[string]mediaType{"application/json": {Schema: s.types.of(s.err)}},
+	}
+	return out
+}
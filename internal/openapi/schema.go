@@ -0,0 +1,154 @@
+package openapi
+
+import (
+	"encoding"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Schema is a JSON schema as OpenAPI 3.0 uses it.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// schemas turns Go types into schemas, collecting named struct types as
+// components so each is described once.
+type schemas struct {
+	defs  map[string]*Schema
+	names map[reflect.Type]string
+}
+
+func newSchemas() *schemas {
+	return &schemas{defs: map[string]*Schema{}, names: map[reflect.Type]string{}}
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// of returns the schema for v's type.
+func (s *schemas) of(v any) *Schema {
+	return s.schema(reflect.TypeOf(v))
+}
+
+func (s *schemas) schema(t reflect.Type) *Schema {
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t.Kind() == reflect.Pointer:
+		sch := *s.schema(t.Elem())
+		if sch.Ref != "" {
+			// $ref can't carry siblings in OpenAPI 3.0; a missing pointer
+			// field is omitted or null either way.
+			return &sch
+		}
+		sch.Nullable = true
+		return &sch
+	case t.Implements(textMarshalerType):
+		// encoding/json writes these as strings.
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: s.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.schema(t.Elem())}
+	case reflect.Struct:
+		return s.structRef(t)
+	default:
+		// interface{}: anything goes.
+		return &Schema{}
+	}
+}
+
+// structRef describes a struct as a component and returns a reference to
+// it. Anonymous structs are described inline.
+func (s *schemas) structRef(t reflect.Type) *Schema {
+	if t.Name() == "" {
+		return s.structSchema(t)
+	}
+	if name, ok := s.names[t]; ok {
+		return &Schema{Ref: "#/components/schemas/" + name}
+	}
+
+	name := exportName(t.Name())
+	if _, taken := s.defs[name]; taken {
+		// Same name in another package.
+		pkg := t.PkgPath()
+		name = exportName(pkg[strings.LastIndex(pkg, "/")+1:]) + name
+	}
+	s.names[t] = name
+	s.defs[name] = nil // reserve, for recursive types
+	s.defs[name] = s.structSchema(t)
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+func (s *schemas) structSchema(t reflect.Type) *Schema {
+	out := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	s.addFields(out, t)
+	return out
+}
+
+// addFields adds t's fields to out the way encoding/json would encode them,
+// flattening embedded structs.
+func (s *schemas) addFields(out *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		ft := f.Type
+		if f.Anonymous && name == "" {
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				s.addFields(out, ft)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		out.Properties[name] = s.schema(ft)
+		optional := strings.Contains(opts, "omitempty") || strings.Contains(opts, "omitzero")
+		if !optional && ft.Kind() != reflect.Pointer {
+			out.Required = append(out.Required, name)
+		}
+	}
+}
+
+// exportName upper-cases the first letter, so unexported Go types still get
+// conventional schema names.
+func exportName(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
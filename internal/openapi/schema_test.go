@@ -0,0 +1,71 @@
+package openapi
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+type inner struct {
+	Note string `json:"note"`
+}
+
+type base struct {
+	ID string `json:"id"`
+}
+
+type sample struct {
+	base
+	Name     string         `json:"name"`
+	Tags     []string       `json:"tags,omitempty"`
+	When     time.Time      `json:"when,omitzero"`
+	Inner    *inner         `json:"inner"`
+	Counts   map[string]int `json:"counts"`
+	Skipped  string         `json:"-"`
+	hidden   string
+	Untagged bool
+	Label    textLabel `json:"label"`
+}
+
+type textLabel struct{ s string }
+
+func (l textLabel) MarshalText() ([]byte, error) { return []byte(l.s), nil }
+
+func TestSchemas_Struct(t *testing.T) {
+	s := newSchemas()
+	ref := s.of(sample{})
+	if ref.Ref != "#/components/schemas/Sample" {
+		t.Fatalf("ref=%q", ref.Ref)
+	}
+
+	sch := s.defs["Sample"]
+	for _, name := range []string{"id", "name", "tags", "when", "inner", "counts", "Untagged", "label"} {
+		if sch.Properties[name] == nil {
+			t.Fatalf("missing property %q in %v", name, sch.Properties)
+		}
+	}
+	if len(sch.Properties) != 8 {
+		t.Fatalf("properties=%v", sch.Properties)
+	}
+
+	wantRequired := []string{"id", "name", "counts", "Untagged", "label"}
+	if !slices.Equal(sch.Required, wantRequired) {
+		t.Fatalf("required=%v, want %v", sch.Required, wantRequired)
+	}
+
+	if p := sch.Properties["when"]; p.Type != "string" || p.Format != "date-time" {
+		t.Fatalf("when=%+v", p)
+	}
+	if p := sch.Properties["label"]; p.Type != "string" || p.Ref != "" {
+		t.Fatalf("label=%+v", p)
+	}
+	if p := sch.Properties["inner"]; p.Ref != "#/components/schemas/Inner" {
+		t.Fatalf("inner=%+v", p)
+	}
+	if p := sch.Properties["counts"]; p.Type != "object" || p.AdditionalProperties.Type != "integer" {
+		t.Fatalf("counts=%+v", p)
+	}
+	if _, ok := s.defs["Inner"]; !ok {
+		t.Fatalf("Inner not collected: %v", s.defs)
+	}
+}
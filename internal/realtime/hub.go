@@ -0,0 +1,276 @@
+// Package realtime fans session changes, cursors and presence out to
+// everyone solving the same session.
+package realtime
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/danny-molnar/crossword/internal/domain"
+)
+
+// EventType names an event on a session's channel.
+type EventType string
+
+const (
+	// EventSnapshot carries the whole session. It is the first event on
+	// every channel, and is sent again for changes that aren't plain cell
+	// edits (checks, reveals, migrations, completion, the timer).
+	EventSnapshot EventType = "snapshot"
+	// EventCells carries the cells and pencil marks an update changed.
+	EventCells EventType = "cells"
+	// EventCursor carries one participant's new cursor.
+	EventCursor EventType = "cursor"
+	// EventPresence carries everyone connected, sent when someone joins or
+	// leaves.
+	EventPresence EventType = "presence"
+)
+
+// Cursor is where a participant is in the grid.
+type Cursor struct {
+	Cell string           `json:"cell"` // "r,c"
+	Dir  domain.Direction `json:"dir,omitempty"`
+}
+
+type Participant struct {
+	ID     string  `json:"id"`
+	Name   string  `json:"name,omitempty"`
+	Cursor *Cursor `json:"cursor,omitempty"`
+}
+
+// Event is one message on a session's channel. Which fields are set depends
+// on Type.
+type Event struct {
+	Type EventType `json:"type"`
+
+	// Version is the session version the event brings a client up to.
+	// Snapshot and cells events carry it; clients can drop any cells event
+	// at or below the version they already have.
+	Version int `json:"version,omitempty"`
+
+	Session *domain.SolveSession `json:"session,omitempty"` // snapshot
+
+	// Cells maps each changed cell to its new value, "" if cleared.
+	Cells map[string]string `json:"cells,omitempty"`
+	// Pencil maps each cell whose pencil mark changed to its new state.
+	Pencil map[string]bool `json:"pencil,omitempty"`
+
+	Participant  *Participant  `json:"participant,omitempty"`  // cursor
+	Participants []Participant `json:"participants,omitempty"` // presence
+}
+
+// subscriberBuffer is how many events a subscriber may fall behind by before
+// it is dropped. A dropped client reconnects and starts from a snapshot.
+const subscriberBuffer = 64
+
+type subscriber struct {
+	participant string
+	token       string
+	ch          chan Event
+}
+
+type member struct {
+	Participant
+	conns int
+}
+
+type room struct {
+	subs    map[*subscriber]struct{}
+	members map[string]*member
+	tokens  map[string]*subscriber // connection token -> its subscriber
+}
+
+// Hub tracks who is connected to which session and delivers events to
+// them. Edits themselves go through the session store; SessionUpdated turns
+// each stored update into an event, so every client sees edits in the order
+// the store applied them.
+type Hub struct {
+	mu    sync.Mutex
+	rooms map[string]*room
+}
+
+func NewHub() *Hub {
+	return &Hub{rooms: make(map[string]*room)}
+}
+
+// Join subscribes a participant to a session's events and announces them to
+// the others. The same participant may join several times (one per tab);
+// they stay present until the last one leaves. Call leave when done; the
+// returned channel is also closed if the subscriber falls too far behind.
+//
+// token is a secret for this connection. Participant IDs are visible to
+// everyone in the session, so SetCursor takes the token as proof that the
+// caller holds the participant's connection.
+func (h *Hub) Join(sid string, p Participant) (events <-chan Event, token string, leave func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	rm := h.rooms[sid]
+	if rm == nil {
+		rm = &room{subs: map[*subscriber]struct{}{}, members: map[string]*member{}, tokens: map[string]*subscriber{}}
+		h.rooms[sid] = rm
+	}
+	m := rm.members[p.ID]
+	if m == nil {
+		m = &member{Participant: Participant{ID: p.ID, Name: p.Name}}
+		rm.members[p.ID] = m
+	}
+	m.conns++
+
+	sub := &subscriber{participant: p.ID, token: newToken(), ch: make(chan Event, subscriberBuffer)}
+	rm.subs[sub] = struct{}{}
+	rm.tokens[sub.token] = sub
+	h.broadcast(rm, presenceEvent(rm))
+
+	var once sync.Once
+	return sub.ch, sub.token, func() {
+		once.Do(func() { h.leave(sid, sub) })
+	}
+}
+
+func newToken() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b) // never fails
+	return hex.EncodeToString(b)
+}
+
+func (h *Hub) leave(sid string, sub *subscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	rm := h.rooms[sid]
+	if rm == nil {
+		return
+	}
+	if _, ok := rm.subs[sub]; ok {
+		delete(rm.subs, sub)
+		close(sub.ch)
+	}
+	delete(rm.tokens, sub.token)
+	if m := rm.members[sub.participant]; m != nil {
+		if m.conns--; m.conns <= 0 {
+			delete(rm.members, sub.participant)
+		}
+	}
+	if len(rm.members) == 0 {
+		delete(h.rooms, sid)
+		return
+	}
+	h.broadcast(rm, presenceEvent(rm))
+}
+
+// SetCursor moves a participant's cursor and tells everyone in the session.
+// token must be one Join issued to that participant for a connection that
+// is still open; otherwise SetCursor reports false.
+func (h *Hub) SetCursor(sid, participant, token string, c Cursor) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	rm := h.rooms[sid]
+	if rm == nil || rm.members[participant] == nil {
+		return false
+	}
+	if sub := rm.tokens[token]; sub == nil || sub.participant != participant {
+		return false
+	}
+	m := rm.members[participant]
+	m.Cursor = &c
+	p := m.Participant
+	h.broadcast(rm, Event{Type: EventCursor, Participant: &p})
+	return true
+}
+
+// Participants lists who is connected to a session, ordered by ID.
+func (h *Hub) Participants(sid string) []Participant {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	rm := h.rooms[sid]
+	if rm == nil {
+		return []Participant{}
+	}
+	return participants(rm)
+}
+
+// SessionUpdated is a store.SessionStore update observer. It sends a cells
+// event when only the fill or pencil marks changed, and a snapshot
+// otherwise.
+func (h *Hub) SessionUpdated(before, after domain.SolveSession) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	rm := h.rooms[after.ID]
+	if rm == nil {
+		return
+	}
+	h.broadcast(rm, updateEvent(before, after))
+}
+
+func updateEvent(before, after domain.SolveSession) Event {
+	if before.PuzzleRevision != after.PuzzleRevision ||
+		before.ChecksUsed != after.ChecksUsed ||
+		before.RevealsUsed != after.RevealsUsed ||
+		!maps.Equal(before.Checked, after.Checked) ||
+		!maps.Equal(before.Revealed, after.Revealed) ||
+		!before.CompletedAt.Equal(after.CompletedAt) ||
+		before.TimerElapsedMs != after.TimerElapsedMs ||
+		!before.TimerRunningSince.Equal(after.TimerRunningSince) {
+		return Snapshot(after)
+	}
+
+	ev := Event{Type: EventCells, Version: after.Version, Cells: map[string]string{}, Pencil: map[string]bool{}}
+	for k, v := range after.GridState {
+		if before.GridState[k] != v {
+			ev.Cells[k] = v
+		}
+	}
+	for k := range before.GridState {
+		if _, ok := after.GridState[k]; !ok {
+			ev.Cells[k] = ""
+		}
+	}
+	for k, v := range after.Pencil {
+		if before.Pencil[k] != v {
+			ev.Pencil[k] = v
+		}
+	}
+	for k := range before.Pencil {
+		if _, ok := after.Pencil[k]; !ok {
+			ev.Pencil[k] = false
+		}
+	}
+	return ev
+}
+
+// Snapshot builds the snapshot event for a session.
+func Snapshot(sess domain.SolveSession) Event {
+	return Event{Type: EventSnapshot, Version: sess.Version, Session: &sess}
+}
+
+func presenceEvent(rm *room) Event {
+	return Event{Type: EventPresence, Participants: participants(rm)}
+}
+
+func participants(rm *room) []Participant {
+	out := make([]Participant, 0, len(rm.members))
+	for _, id := range slices.Sorted(maps.Keys(rm.members)) {
+		out = append(out, rm.members[id].Participant)
+	}
+	return out
+}
+
+// broadcast sends ev to every subscriber of rm without blocking, dropping
+// any that are too far behind. h.mu must be held.
+func (h *Hub) broadcast(rm *room, ev Event) {
+	for sub := range rm.subs {
+		select {
+		case sub.ch <- ev:
+		default:
+			delete(rm.subs, sub)
+			close(sub.ch)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package realtime
+
+import (
+	"testing"
+
+	"github.com/danny-molnar/crossword/internal/domain"
+)
+
+func next(t *testing.T, ch <-chan Event) Event {
+	t.Helper()
+	select {
+	case ev, ok := <-ch:
+		if !ok {
+			t.Fatalf("channel closed")
+		}
+		return ev
+	default:
+		t.Fatalf("no event")
+		return Event{}
+	}
+}
+
+func TestHub_Presence(t *testing.T) {
+	h := NewHub()
+
+	a, tokenA, leaveA := h.Join("s1", Participant{ID: "a", Name: "Ann"})
+	if ev := next(t, a); ev.Type != EventPresence || len(ev.Participants) != 1 {
+		t.Fatalf("join event=%+v", ev)
+	}
+
+	b, tokenB, leaveB := h.Join("s1", Participant{ID: "b"})
+	next(t, b)
+	if ev := next(t, a); len(ev.Participants) != 2 || ev.Participants[1].ID != "b" {
+		t.Fatalf("second join seen by a=%+v", ev)
+	}
+
+	if !h.SetCursor("s1", "b", tokenB, Cursor{Cell: "0,1", Dir: domain.Down}) {
+		t.Fatalf("SetCursor for connected participant failed")
+	}
+	if h.SetCursor("s1", "nobody", tokenB, Cursor{Cell: "0,0"}) {
+		t.Fatalf("SetCursor for unknown participant succeeded")
+	}
+	// A participant can't move someone else's cursor.
+	if h.SetCursor("s1", "b", tokenA, Cursor{Cell: "0,0"}) || h.SetCursor("s1", "b", "", Cursor{Cell: "0,0"}) {
+		t.Fatalf("SetCursor without b's token succeeded")
+	}
+	if ev := next(t, a); ev.Type != EventCursor || ev.Participant.ID != "b" || ev.Participant.Cursor.Cell != "0,1" {
+		t.Fatalf("cursor event=%+v", ev)
+	}
+	next(t, b)
+
+	leaveB()
+	leaveB() // safe to call twice
+	if ev := next(t, a); ev.Type != EventPresence || len(ev.Participants) != 1 {
+		t.Fatalf("leave event=%+v", ev)
+	}
+	if _, ok := <-b; ok {
+		t.Fatalf("channel not closed after leave")
+	}
+
+	leaveA()
+	if got := h.Participants("s1"); len(got) != 0 {
+		t.Fatalf("Participants after everyone left=%v", got)
+	}
+}
+
+func TestHub_SessionUpdated(t *testing.T) {
+	h := NewHub()
+	ch, _, leave := h.Join("s1", Participant{ID: "a"})
+	defer leave()
+	next(t, ch)
+
+	before := domain.SolveSession{
+		ID:        "s1",
+		Version:   1,
+		GridState: map[string]string{"0,0": "A", "0,1": "B"},
+		Pencil:    map[string]bool{"0,1": true},
+	}
+	after := before
+	after.Version = 2
+	after.GridState = map[string]string{"0,0": "C", "0,2": "D"}
+	after.Pencil = map[string]bool{}
+
+	h.SessionUpdated(before, after)
+	ev := next(t, ch)
+	if ev.Type != EventCells || ev.Version != 2 {
+		t.Fatalf("update event=%+v", ev)
+	}
+	if len(ev.Cells) != 3 || ev.Cells["0,0"] != "C" || ev.Cells["0,1"] != "" || ev.Cells["0,2"] != "D" {
+		t.Fatalf("cells=%v", ev.Cells)
+	}
+	if v, ok := ev.Pencil["0,1"]; !ok || v {
+		t.Fatalf("pencil=%v", ev.Pencil)
+	}
+
+	// Anything beyond the fill sends the whole session.
+	revealed := after
+	revealed.Version = 3
+	revealed.RevealsUsed = 1
+	revealed.Revealed = map[string]bool{"0,0": true}
+	h.SessionUpdated(after, revealed)
+	if ev := next(t, ch); ev.Type != EventSnapshot || ev.Session == nil || ev.Session.Version != 3 {
+		t.Fatalf("reveal event=%+v", ev)
+	}
+}
+
+func TestHub_DropsSlowSubscribers(t *testing.T) {
+	h := NewHub()
+	ch, _, leave := h.Join("s1", Participant{ID: "a"})
+	defer leave()
+
+	sess := domain.SolveSession{ID: "s1"}
+	for i := 0; i < subscriberBuffer+1; i++ {
+		h.SessionUpdated(sess, sess)
+	}
+
+	n := 0
+	for range ch {
+		n++
+	}
+	if n != subscriberBuffer {
+		t.Fatalf("got %d events before close, want %d", n, subscriberBuffer)
+	}
+}
@@ -1,5 +1,12 @@
 package store
 
+import "errors"
+
+// ErrVersionMismatch is returned by conditional writes (UpdateIfVersion,
+// PutPuzzleIfRevision) when the stored version is not the one the caller
+// expected. The current value is returned alongside it.
+var ErrVersionMismatch = errors.New("version mismatch")
+
 type MemoryStore struct {
 	Puzzles  *PuzzleStore
 	Sessions *SessionStore
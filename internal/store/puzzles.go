@@ -1,35 +1,200 @@
 package store
 
 import (
+	"encoding/base64"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/danny-molnar/crossword/internal/domain"
 )
 
+// PuzzleStore keeps every revision of every puzzle. Revisions are immutable:
+// PutPuzzle always adds a new one, and callers get copies they can't use to
+// alter what is stored.
 type PuzzleStore struct {
 	mu      sync.RWMutex
-	puzzles map[string]domain.Puzzle
+	puzzles map[string][]domain.Puzzle // id -> revisions, oldest first
+	now     func() time.Time
 }
 
 func NewPuzzleStore() *PuzzleStore {
 	return &PuzzleStore{
-		puzzles: make(map[string]domain.Puzzle),
+		puzzles: make(map[string][]domain.Puzzle),
+		now:     time.Now,
 	}
 }
 
-func (s *PuzzleStore) PutPuzzle(p domain.Puzzle) {
+// PutPuzzle stores p as the next revision of its puzzle and returns it with
+// Revision and CreatedAt set.
+func (s *PuzzleStore) PutPuzzle(p domain.Puzzle) domain.Puzzle {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.puzzles[p.ID] = p
+	return s.put(p)
 }
 
+// put appends p as the next revision. Callers hold s.mu.
+func (s *PuzzleStore) put(p domain.Puzzle) domain.Puzzle {
+	revs := s.puzzles[p.ID]
+	p = p.Clone()
+	p.Revision = len(revs) + 1
+	if len(revs) > 0 {
+		p.CreatedAt = revs[0].CreatedAt
+	} else {
+		p.CreatedAt = s.now().UTC()
+	}
+	s.puzzles[p.ID] = append(revs, p)
+	return p.Clone()
+}
+
+// PutPuzzleIfRevision is PutPuzzle, but only if the puzzle's latest
+// revision is rev; otherwise it returns the latest revision and
+// ErrVersionMismatch. rev 0 expects the puzzle not to exist yet.
+func (s *PuzzleStore) PutPuzzleIfRevision(p domain.Puzzle, rev int) (domain.Puzzle, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	revs := s.puzzles[p.ID]
+	if len(revs) != rev {
+		if len(revs) == 0 {
+			return domain.Puzzle{}, fmt.Errorf("puzzle not found")
+		}
+		return revs[len(revs)-1].Clone(), ErrVersionMismatch
+	}
+	return s.put(p), nil
+}
+
+// GetPuzzle returns the latest revision of a puzzle.
 func (s *PuzzleStore) GetPuzzle(id string) (domain.Puzzle, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	p, ok := s.puzzles[id]
+	revs, ok := s.puzzles[id]
+	if !ok {
+		return domain.Puzzle{}, fmt.Errorf("puzzle not found")
+	}
+	return revs[len(revs)-1].Clone(), nil
+}
+
+// GetRevision returns a specific revision of a puzzle.
+func (s *PuzzleStore) GetRevision(id string, rev int) (domain.Puzzle, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	revs, ok := s.puzzles[id]
 	if !ok {
 		return domain.Puzzle{}, fmt.Errorf("puzzle not found")
 	}
-	return p, nil
+	if rev < 1 || rev > len(revs) {
+		return domain.Puzzle{}, fmt.Errorf("revision %d of puzzle %s not found", rev, id)
+	}
+	return revs[rev-1].Clone(), nil
+}
+
+// PuzzleFilter narrows a puzzle listing. Zero fields match everything.
+type PuzzleFilter struct {
+	Type   domain.PuzzleType
+	Rows   int
+	Cols   int
+	From   domain.Date // publication date range, inclusive
+	To     domain.Date
+	Author string // case-insensitive substring
+}
+
+func (f PuzzleFilter) match(p domain.Puzzle) bool {
+	if f.Type != "" && p.Type != f.Type {
+		return false
+	}
+	if f.Rows > 0 && p.Rows != f.Rows {
+		return false
+	}
+	if f.Cols > 0 && p.Cols != f.Cols {
+		return false
+	}
+	if !f.From.IsZero() || !f.To.IsZero() {
+		if p.PublishedOn.IsZero() {
+			return false
+		}
+		if !f.From.IsZero() && p.PublishedOn.Before(f.From) {
+			return false
+		}
+		if !f.To.IsZero() && p.PublishedOn.After(f.To) {
+			return false
+		}
+	}
+	if f.Author != "" && !strings.Contains(strings.ToLower(p.Author), strings.ToLower(f.Author)) {
+		return false
+	}
+	return true
+}
+
+// List returns the latest revision of up to limit puzzles matching f,
+// newest first: by CreatedAt, then by ID for puzzles created at the same
+// moment. Pass the returned cursor back as after to get the next page; it
+// is empty once there are no more. A cursor List didn't return is an error.
+func (s *PuzzleStore) List(f PuzzleFilter, after string, limit int) ([]domain.Puzzle, string, error) {
+	var from listKey
+	if after != "" {
+		var err error
+		if from, err = parseListCursor(after); err != nil {
+			return nil, "", err
+		}
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	latest := make([]domain.Puzzle, 0, len(s.puzzles))
+	for _, revs := range s.puzzles {
+		p := revs[len(revs)-1]
+		if after == "" || keyOf(p).before(from) {
+			latest = append(latest, p)
+		}
+	}
+	sort.Slice(latest, func(i, j int) bool { return keyOf(latest[j]).before(keyOf(latest[i])) })
+
+	var out []domain.Puzzle
+	for _, p := range latest {
+		if !f.match(p) {
+			continue
+		}
+		if len(out) == limit {
+			return out, keyOf(out[len(out)-1]).cursor(), nil
+		}
+		out = append(out, p.Clone())
+	}
+	return out, "", nil
+}
+
+// listKey is a puzzle's place in List's order.
+type listKey struct {
+	created time.Time
+	id      string
+}
+
+func keyOf(p domain.Puzzle) listKey { return listKey{created: p.CreatedAt, id: p.ID} }
+
+// before reports whether k is older than o.
+func (k listKey) before(o listKey) bool {
+	if !k.created.Equal(o.created) {
+		return k.created.Before(o.created)
+	}
+	return k.id < o.id
+}
+
+func (k listKey) cursor() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(k.created.UnixNano(), 10) + "." + k.id))
+}
+
+func parseListCursor(cursor string) (listKey, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return listKey{}, fmt.Errorf("invalid cursor")
+	}
+	nanos, id, ok := strings.Cut(string(raw), ".")
+	n, err := strconv.ParseInt(nanos, 10, 64)
+	if !ok || err != nil {
+		return listKey{}, fmt.Errorf("invalid cursor")
+	}
+	return listKey{created: time.Unix(0, n).UTC(), id: id}, nil
 }
@@ -0,0 +1,136 @@
+package store
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danny-molnar/crossword/internal/domain"
+)
+
+func TestPuzzleStore_Revisions(t *testing.T) {
+	s := NewPuzzleStore()
+
+	p := domain.Puzzle{ID: "p1", Title: "First"}
+	if got := s.PutPuzzle(p); got.Revision != 1 {
+		t.Fatalf("first revision=%d want 1", got.Revision)
+	}
+	p.Title = "Second"
+	if got := s.PutPuzzle(p); got.Revision != 2 {
+		t.Fatalf("second revision=%d want 2", got.Revision)
+	}
+
+	latest, err := s.GetPuzzle("p1")
+	if err != nil || latest.Title != "Second" || latest.Revision != 2 {
+		t.Fatalf("GetPuzzle=%+v, %v", latest, err)
+	}
+
+	first, err := s.GetRevision("p1", 1)
+	if err != nil || first.Title != "First" {
+		t.Fatalf("GetRevision(1)=%+v, %v", first, err)
+	}
+	if _, err := s.GetRevision("p1", 3); err == nil {
+		t.Fatalf("expected error for missing revision")
+	}
+
+	// Stored revisions can't be changed through returned copies.
+	first.Title = "Changed"
+	if again, _ := s.GetRevision("p1", 1); again.Title != "First" {
+		t.Fatalf("stored revision was modified: %+v", again)
+	}
+}
+
+func TestPuzzleStore_List(t *testing.T) {
+	s := NewPuzzleStore()
+	day := func(d int) domain.Date { return domain.NewDate(2024, time.January, d) }
+
+	clock := time.Date(2024, time.February, 1, 9, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { clock = clock.Add(time.Minute); return clock }
+
+	// Created in this order; IDs don't sort by age, as library IDs don't.
+	s.PutPuzzle(domain.Puzzle{ID: "01D", Type: domain.PuzzleQuick, Rows: 5, Cols: 5, Author: "Rufus", PublishedOn: day(1)})
+	s.PutPuzzle(domain.Puzzle{ID: "01B", Type: domain.PuzzleCryptic, Rows: 15, Cols: 15, Author: "Azed", PublishedOn: day(2)})
+	s.PutPuzzle(domain.Puzzle{ID: "01C", Type: domain.PuzzleCryptic, Rows: 15, Cols: 15, Author: "Paul", PublishedOn: day(3)})
+	s.PutPuzzle(domain.Puzzle{ID: "01A", Type: domain.PuzzleCryptic, Rows: 12, Cols: 12, Author: "Azed"})
+	// A new revision keeps its puzzle's place.
+	if p := s.PutPuzzle(domain.Puzzle{ID: "01D", Type: domain.PuzzleQuick, Rows: 5, Cols: 5, Author: "Rufus", PublishedOn: day(1)}); !p.CreatedAt.Equal(time.Date(2024, time.February, 1, 9, 1, 0, 0, time.UTC)) {
+		t.Fatalf("revision 2 CreatedAt = %v, want the first revision's", p.CreatedAt)
+	}
+
+	ids := func(ps []domain.Puzzle) string {
+		var out []string
+		for _, p := range ps {
+			out = append(out, p.ID)
+		}
+		return strings.Join(out, ",")
+	}
+
+	page, next, err := s.List(PuzzleFilter{}, "", 3)
+	if err != nil || ids(page) != "01A,01C,01B" || next == "" {
+		t.Fatalf("page 1 = %s next=%q err=%v", ids(page), next, err)
+	}
+	page, next, err = s.List(PuzzleFilter{}, next, 3)
+	if err != nil || ids(page) != "01D" || next != "" {
+		t.Fatalf("page 2 = %s next=%q err=%v", ids(page), next, err)
+	}
+	if _, _, err := s.List(PuzzleFilter{}, "not-a-cursor", 3); err == nil {
+		t.Fatal("List with a bad cursor: want error")
+	}
+
+	// Puzzles created at the same moment fall back to ID order, and the
+	// cursor pages through them without skipping or repeating any.
+	same := NewPuzzleStore()
+	same.now = func() time.Time { return clock }
+	for _, id := range []string{"b", "c", "a"} {
+		same.PutPuzzle(domain.Puzzle{ID: id})
+	}
+	var got []string
+	for cursor := ""; ; {
+		page, next, err := same.List(PuzzleFilter{}, cursor, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, ids(page))
+		if cursor = next; cursor == "" {
+			break
+		}
+	}
+	if strings.Join(got, "|") != "c,b|a" {
+		t.Fatalf("pages = %v", got)
+	}
+
+	tests := []struct {
+		f    PuzzleFilter
+		want string
+	}{
+		{PuzzleFilter{Type: domain.PuzzleCryptic, Rows: 15, Cols: 15}, "01C,01B"},
+		{PuzzleFilter{Author: "azed"}, "01A,01B"},
+		{PuzzleFilter{From: day(2), To: day(3)}, "01C,01B"},
+		{PuzzleFilter{To: day(1)}, "01D"},
+	}
+	for _, tt := range tests {
+		if page, _, _ := s.List(tt.f, "", 10); ids(page) != tt.want {
+			t.Fatalf("List(%+v) = %s want %s", tt.f, ids(page), tt.want)
+		}
+	}
+}
+
+func TestPuzzleStore_PutIfRevision(t *testing.T) {
+	s := NewPuzzleStore()
+
+	if _, err := s.PutPuzzleIfRevision(domain.Puzzle{ID: "p1"}, 1); err == nil {
+		t.Fatalf("expected error for missing puzzle")
+	}
+	if got, err := s.PutPuzzleIfRevision(domain.Puzzle{ID: "p1", Title: "First"}, 0); err != nil || got.Revision != 1 {
+		t.Fatalf("create=%+v, %v", got, err)
+	}
+	if got, err := s.PutPuzzleIfRevision(domain.Puzzle{ID: "p1", Title: "Second"}, 1); err != nil || got.Revision != 2 {
+		t.Fatalf("update=%+v, %v", got, err)
+	}
+
+	cur, err := s.PutPuzzleIfRevision(domain.Puzzle{ID: "p1", Title: "Stale"}, 1)
+	if !errors.Is(err, ErrVersionMismatch) || cur.Title != "Second" || cur.Revision != 2 {
+		t.Fatalf("stale update=%+v, %v", cur, err)
+	}
+}
@@ -11,6 +11,18 @@ import (
 type SessionStore struct {
 	mu       sync.RWMutex
 	sessions map[string]domain.SolveSession
+
+	observers []func(before, after domain.SolveSession)
+}
+
+// OnUpdate registers fn to be called after every successful update with the
+// session before and after it. Observers run under the store lock, so they
+// see updates in version order, but they must not block or call back into
+// the store.
+func (s *SessionStore) OnUpdate(fn func(before, after domain.SolveSession)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.observers = append(s.observers, fn)
 }
 
 func NewSessionStore() *SessionStore {
@@ -19,10 +31,13 @@ func NewSessionStore() *SessionStore {
 	}
 }
 
-func (s *SessionStore) Create(sess domain.SolveSession) {
+// Create stores a new session at version 1.
+func (s *SessionStore) Create(sess domain.SolveSession) domain.SolveSession {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	sess.Version = 1
 	s.sessions[sess.ID] = sess
+	return sess
 }
 
 func (s *SessionStore) Get(id string) (domain.SolveSession, error) {
@@ -35,7 +50,16 @@ func (s *SessionStore) Get(id string) (domain.SolveSession, error) {
 	return v, nil
 }
 
+// Update applies update to a session whatever its version, and bumps the
+// version.
 func (s *SessionStore) Update(id string, update func(domain.SolveSession) domain.SolveSession) (domain.SolveSession, error) {
+	return s.UpdateIfVersion(id, 0, update)
+}
+
+// UpdateIfVersion is Update, but only if the session is at the given
+// version; otherwise it returns the current session and
+// ErrVersionMismatch without calling update. Version 0 matches any version.
+func (s *SessionStore) UpdateIfVersion(id string, version int, update func(domain.SolveSession) domain.SolveSession) (domain.SolveSession, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -43,9 +67,19 @@ func (s *SessionStore) Update(id string, update func(domain.SolveSession) domain
 	if !ok {
 		return domain.SolveSession{}, fmt.Errorf("session not found")
 	}
+	if version != 0 && cur.Version != version {
+		return cur, ErrVersionMismatch
+	}
 
-	cur = update(cur)
+	// update gets its own copy, so writing to its maps can't change the
+	// stored session (or what observers see as before).
+	before := cur
+	cur = update(cur.Clone())
+	cur.Version++
 	cur.UpdatedAt = time.Now().UTC()
 	s.sessions[id] = cur
+	for _, fn := range s.observers {
+		fn(before, cur)
+	}
 	return cur, nil
 }
@@ -0,0 +1,63 @@
+package store
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/danny-molnar/crossword/internal/domain"
+)
+
+func TestSessionStore_UpdateIfVersion(t *testing.T) {
+	s := NewSessionStore()
+	if got := s.Create(domain.SolveSession{ID: "s1"}); got.Version != 1 {
+		t.Fatalf("created version=%d want 1", got.Version)
+	}
+
+	setA := func(cur domain.SolveSession) domain.SolveSession {
+		cur.GridState = map[string]string{"0,0": "A"}
+		return cur
+	}
+	got, err := s.UpdateIfVersion("s1", 1, setA)
+	if err != nil || got.Version != 2 {
+		t.Fatalf("UpdateIfVersion(1)=%+v, %v", got, err)
+	}
+
+	called := false
+	cur, err := s.UpdateIfVersion("s1", 1, func(cur domain.SolveSession) domain.SolveSession {
+		called = true
+		return cur
+	})
+	if !errors.Is(err, ErrVersionMismatch) || called || cur.Version != 2 || cur.GridState["0,0"] != "A" {
+		t.Fatalf("stale update=%+v, %v (called=%v)", cur, err, called)
+	}
+
+	// Unconditional updates still bump the version.
+	if got, err := s.Update("s1", setA); err != nil || got.Version != 3 {
+		t.Fatalf("Update=%+v, %v", got, err)
+	}
+	if _, err := s.UpdateIfVersion("missing", 0, setA); err == nil || errors.Is(err, ErrVersionMismatch) {
+		t.Fatalf("expected not-found error, got %v", err)
+	}
+}
+
+func TestSessionStore_UpdateInPlace(t *testing.T) {
+	s := NewSessionStore()
+	s.Create(domain.SolveSession{ID: "s1", GridState: map[string]string{"0,0": "A"}})
+
+	var seen string
+	s.OnUpdate(func(before, after domain.SolveSession) {
+		seen = before.GridState["0,0"] + ">" + after.GridState["0,0"]
+	})
+
+	// Writing straight into the maps update is given doesn't reach the
+	// session the observer sees as before.
+	if _, err := s.Update("s1", func(cur domain.SolveSession) domain.SolveSession {
+		cur.GridState["0,0"] = "B"
+		return cur
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if seen != "A>B" {
+		t.Fatalf("observer saw %s, want A>B", seen)
+	}
+}